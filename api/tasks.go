@@ -342,24 +342,39 @@ func (c *CheckRestart) Merge(o *CheckRestart) *CheckRestart {
 // The ServiceCheck data model represents the consul health check that
 // Nomad registers for a Task
 type ServiceCheck struct {
-	Id            string
-	Name          string
-	Type          string
-	Command       string
-	Args          []string
-	Path          string
-	Protocol      string
-	PortLabel     string `mapstructure:"port"`
-	AddressMode   string `mapstructure:"address_mode"`
-	Interval      time.Duration
-	Timeout       time.Duration
-	InitialStatus string `mapstructure:"initial_status"`
-	TLSSkipVerify bool   `mapstructure:"tls_skip_verify"`
-	Header        map[string][]string
-	Method        string
-	CheckRestart  *CheckRestart `mapstructure:"check_restart"`
-	GRPCService   string        `mapstructure:"grpc_service"`
-	GRPCUseTLS    bool          `mapstructure:"grpc_use_tls"`
+	Id              string
+	Name            string
+	Type            string
+	Command         string
+	Args            []string
+	Path            string
+	Protocol        string
+	PortLabel       string `mapstructure:"port"`
+	AddressMode     string `mapstructure:"address_mode"`
+	Interval        time.Duration
+	Timeout         time.Duration
+	InitialStatus   string `mapstructure:"initial_status"`
+	TLSSkipVerify   bool   `mapstructure:"tls_skip_verify"`
+	Header          map[string][]string
+	Method          string
+	CheckRestart    *CheckRestart `mapstructure:"check_restart"`
+	GRPCService     string        `mapstructure:"grpc_service"`
+	GRPCUseTLS      bool          `mapstructure:"grpc_use_tls"`
+	StreamInterval  time.Duration `mapstructure:"stream_interval"`
+	DefaultOutput   string        `mapstructure:"default_output"`
+	Dedupe          bool          `mapstructure:"dedupe"`
+	KillGrace       time.Duration `mapstructure:"kill_grace"`
+	TruncateFrom    string        `mapstructure:"truncate_from"`
+	Webhook         string        `mapstructure:"webhook"`
+	WebhookInterval time.Duration `mapstructure:"webhook_interval"`
+	StatusNames     map[string]string
+	AlignToClock    bool          `mapstructure:"align_to_clock"`
+	Nice            int           `mapstructure:"nice"`
+	RlimitCPU       time.Duration `mapstructure:"rlimit_cpu"`
+	RlimitMemoryMB  int           `mapstructure:"rlimit_memory"`
+	Base64Output    bool          `mapstructure:"base64_output"`
+	StartOrder      int           `mapstructure:"start_order"`
+	SeparateStderr  bool          `mapstructure:"separate_stderr"`
 }
 
 // The Service model represents a Consul service definition
@@ -2,20 +2,24 @@ package taskrunner
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	log "github.com/hashicorp/go-hclog"
+	tinterfaces "github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
 
 // NewDriverHandle returns a handle for task operations on a specific task
-func NewDriverHandle(driver drivers.DriverPlugin, taskID string, task *structs.Task, net *drivers.DriverNetwork) *DriverHandle {
+func NewDriverHandle(driver drivers.DriverPlugin, taskID string, task *structs.Task, net *drivers.DriverNetwork, logger log.Logger) *DriverHandle {
 	return &DriverHandle{
 		driver: driver,
 		net:    net,
 		taskID: taskID,
 		task:   task,
+		logger: logger,
 	}
 }
 
@@ -26,6 +30,11 @@ type DriverHandle struct {
 	net    *drivers.DriverNetwork
 	task   *structs.Task
 	taskID string
+	logger log.Logger
+
+	// unsupportedOnce limits warnings about exec options the driver can't
+	// apply to one per handle
+	unsupportedOnce sync.Once
 }
 
 func (h *DriverHandle) ID() string {
@@ -61,6 +70,43 @@ func (h *DriverHandle) Exec(timeout time.Duration, cmd string, args []string) ([
 	return res.Stdout, res.ExitResult.ExitCode, res.ExitResult.Err
 }
 
+// ExecWithOptions runs a command as configured by opts. Drivers that can't
+// apply options run the command without them, logging a warning the first
+// time, and return stderr within stdout.
+func (h *DriverHandle) ExecWithOptions(timeout time.Duration, cmd string, args []string, opts tinterfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	res, err := h.execTask(&drivers.ExecOptions{
+		Command:        append([]string{cmd}, args...),
+		Timeout:        timeout,
+		KillGrace:      opts.KillGrace,
+		Nice:           opts.Limits.Nice,
+		CPULimit:       opts.Limits.CPU,
+		MemoryLimitMB:  opts.Limits.MemoryMB,
+		Output:         opts.Output,
+		SeparateStderr: opts.SeparateStderr,
+		KeepHead:       opts.KeepHead,
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return res.Stdout, res.Stderr, res.ExitResult.ExitCode, res.ExitResult.Err
+}
+
+// execTask runs a command with the driver's ExecTaskWithOptions if it
+// implements it, otherwise with ExecTask ignoring the options it can't apply.
+func (h *DriverHandle) execTask(opts *drivers.ExecOptions) (*drivers.ExecTaskResult, error) {
+	if d, ok := h.driver.(drivers.ExecOptionsDriver); ok {
+		return d.ExecTaskWithOptions(h.taskID, opts)
+	}
+
+	if opts.KillGrace != 0 || opts.Nice != 0 || opts.CPULimit != 0 || opts.MemoryLimitMB != 0 || opts.Output != nil || opts.SeparateStderr || opts.KeepHead {
+		h.unsupportedOnce.Do(func() {
+			h.logger.Warn("task driver doesn't support script check exec options; ignoring kill_grace, stream_interval, nice, rlimits, separate_stderr, and truncate_from",
+				"driver", h.task.Driver)
+		})
+	}
+	return h.driver.ExecTask(h.taskID, opts.Command, opts.Timeout)
+}
+
 func (h *DriverHandle) Network() *drivers.DriverNetwork {
 	return h.net
 }
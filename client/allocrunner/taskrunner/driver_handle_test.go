@@ -0,0 +1,206 @@
+package taskrunner
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tinterfaces "github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDriverHandle_ExecWithOptions_KillGrace asserts a command run through the
+// driver handle of a raw_exec task is given a grace period to exit by the
+// executor.
+func TestDriverHandle_ExecWithOptions_KillGrace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test requires SIGTERM")
+	}
+	t.Parallel()
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "raw_exec"
+	task.Config = map[string]interface{}{
+		"command": "sleep",
+		"args":    []string{"1000"},
+	}
+
+	tr, _, cleanup := runTestTaskRunner(t, alloc, task.Name)
+	defer cleanup()
+	testWaitForTaskToStart(t, tr)
+
+	handle := tr.getDriverHandle()
+	require.NotNil(t, handle)
+
+	script := "trap 'echo cleaned up; exit 0' TERM; sleep 10 >/dev/null 2>&1 & wait"
+	opts := tinterfaces.ScriptExecOptions{KillGrace: 5 * time.Second}
+	output, _, _, err := handle.ExecWithOptions(time.Second, "/bin/sh", []string{"-c", script}, opts)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, "cleaned up\n", string(output))
+}
+
+// TestDriverHandle_ExecWithOptions_Limits asserts a command run through the
+// driver handle of a raw_exec task is run within limits by the executor.
+func TestDriverHandle_ExecWithOptions_Limits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Test requires Linux")
+	}
+	t.Parallel()
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "raw_exec"
+	task.Config = map[string]interface{}{
+		"command": "sleep",
+		"args":    []string{"1000"},
+	}
+
+	tr, _, cleanup := runTestTaskRunner(t, alloc, task.Name)
+	defer cleanup()
+	testWaitForTaskToStart(t, tr)
+
+	handle := tr.getDriverHandle()
+	require.NotNil(t, handle)
+
+	opts := tinterfaces.ScriptExecOptions{
+		Limits: tinterfaces.ScriptLimits{
+			Nice: 10,
+			CPU:  30 * time.Second,
+		},
+	}
+	output, _, code, err := handle.ExecWithOptions(5*time.Second, "/bin/sh", []string{"-c", "nice; ulimit -t"}, opts)
+	require.NoError(t, err)
+	require.Zero(t, code)
+	require.Equal(t, "10\n30\n", string(output))
+}
+
+// TestDriverHandle_ExecWithOptions_Output asserts output of a command run
+// through the driver handle of a raw_exec task is streamed while the command
+// runs.
+func TestDriverHandle_ExecWithOptions_Output(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test requires sh")
+	}
+	t.Parallel()
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "raw_exec"
+	task.Config = map[string]interface{}{
+		"command": "sleep",
+		"args":    []string{"1000"},
+	}
+
+	tr, _, cleanup := runTestTaskRunner(t, alloc, task.Name)
+	defer cleanup()
+	testWaitForTaskToStart(t, tr)
+
+	handle := tr.getDriverHandle()
+	require.NotNil(t, handle)
+
+	// Record when each chunk is received to assert the first was streamed
+	// before the command exited
+	var (
+		chunks   []string
+		received []time.Time
+		lock     sync.Mutex
+	)
+	output := func(p []byte) {
+		lock.Lock()
+		defer lock.Unlock()
+		chunks = append(chunks, string(p))
+		received = append(received, time.Now())
+	}
+
+	script := "echo first; sleep 1; echo second"
+	opts := tinterfaces.ScriptExecOptions{Output: output}
+	out, _, code, err := handle.ExecWithOptions(5*time.Second, "/bin/sh", []string{"-c", script}, opts)
+	exited := time.Now()
+	require.NoError(t, err)
+	require.Zero(t, code)
+	require.Equal(t, "first\nsecond\n", string(out))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, "first\nsecond\n", strings.Join(chunks, ""))
+	require.Equal(t, "first\n", chunks[0])
+	require.True(t, exited.Sub(received[0]) > 500*time.Millisecond,
+		"first chunk received %v before exit", exited.Sub(received[0]))
+}
+
+// TestDriverHandle_ExecWithOptions asserts stderr of a command run through the
+// driver handle of a raw_exec task is returned separately from stdout.
+func TestDriverHandle_ExecWithOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test requires sh")
+	}
+	t.Parallel()
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "raw_exec"
+	task.Config = map[string]interface{}{
+		"command": "sleep",
+		"args":    []string{"1000"},
+	}
+
+	tr, _, cleanup := runTestTaskRunner(t, alloc, task.Name)
+	defer cleanup()
+	testWaitForTaskToStart(t, tr)
+
+	handle := tr.getDriverHandle()
+	require.NotNil(t, handle)
+
+	var streamed []byte
+	opts := tinterfaces.ScriptExecOptions{
+		KillGrace:      time.Second,
+		Output:         func(p []byte) { streamed = append(streamed, p...) },
+		SeparateStderr: true,
+	}
+	script := "echo out; echo err >&2; exit 1"
+	stdout, stderr, code, err := handle.ExecWithOptions(5*time.Second, "/bin/sh", []string{"-c", script}, opts)
+	require.NoError(t, err)
+	require.Equal(t, 1, code)
+	require.Equal(t, "out\n", string(stdout))
+	require.Equal(t, "err\n", string(stderr))
+	require.Equal(t, "out\n", string(streamed))
+}
+
+// TestDriverHandle_ExecWithOptions_KeepHead asserts the executor of a raw_exec
+// task keeps the beginning of output too long to return in full when asked.
+func TestDriverHandle_ExecWithOptions_KeepHead(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test requires sh")
+	}
+	t.Parallel()
+
+	alloc := mock.BatchAlloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "raw_exec"
+	task.Config = map[string]interface{}{
+		"command": "sleep",
+		"args":    []string{"1000"},
+	}
+
+	tr, _, cleanup := runTestTaskRunner(t, alloc, task.Name)
+	defer cleanup()
+	testWaitForTaskToStart(t, tr)
+
+	handle := tr.getDriverHandle()
+	require.NotNil(t, handle)
+
+	// The script writes more output than the executor returns
+	script := `echo head; i=0; while [ $i -lt 1000 ]; do echo xxxxxxxxx; i=$((i+1)); done; echo tail`
+	opts := tinterfaces.ScriptExecOptions{KeepHead: true}
+	out, _, code, err := handle.ExecWithOptions(5*time.Second, "/bin/sh", []string{"-c", script}, opts)
+	require.NoError(t, err)
+	require.Zero(t, code)
+	require.Len(t, out, drivers.CheckBufSize)
+	require.True(t, strings.HasPrefix(string(out), "head\n"), "output doesn't start with head: %q", out[:10])
+}
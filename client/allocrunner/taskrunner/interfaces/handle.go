@@ -9,3 +9,43 @@ import (
 type ScriptExecutor interface {
 	Exec(timeout time.Duration, cmd string, args []string) ([]byte, int, error)
 }
+
+// ScriptLimits lower the priority of and bound the resources used by a
+// command run by an OptionsScriptExecutor. Zero values leave the command's
+// priority or resource unchanged.
+type ScriptLimits struct {
+	Nice     int
+	CPU      time.Duration
+	MemoryMB int
+}
+
+// ScriptExecOptions configure how an OptionsScriptExecutor runs a command.
+// The zero value runs it like Exec.
+type ScriptExecOptions struct {
+	// KillGrace is how long a command still running at its timeout is given
+	// to exit after being signaled before it's killed
+	KillGrace time.Duration
+
+	// Limits the command is run within
+	Limits ScriptLimits
+
+	// Output, if set, is called with each chunk of output as it is read. The
+	// full output is still returned once the command exits.
+	Output func([]byte)
+
+	// SeparateStderr returns stderr separately from stdout rather than
+	// interleaved with it. Output is then only called with stdout.
+	SeparateStderr bool
+
+	// KeepHead keeps the beginning of output too long to return in full
+	// rather than the end.
+	KeepHead bool
+}
+
+// OptionsScriptExecutor is a ScriptExecutor that can also run a command as
+// configured by ScriptExecOptions. Executors that can't honor an option log a
+// warning and run the command without it.
+type OptionsScriptExecutor interface {
+	ScriptExecutor
+	ExecWithOptions(timeout time.Duration, cmd string, args []string, opts ScriptExecOptions) (stdout []byte, stderr []byte, code int, err error)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	log "github.com/hashicorp/go-hclog"
+	tinterfaces "github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	bstructs "github.com/hashicorp/nomad/plugins/base/structs"
 )
@@ -128,6 +129,29 @@ TRY:
 	return out, c, err
 }
 
+func (l *LazyHandle) ExecWithOptions(timeout time.Duration, cmd string, args []string, opts tinterfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	h, err := l.getHandle()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Only retry once
+	first := true
+
+TRY:
+	out, stderr, c, err := h.ExecWithOptions(timeout, cmd, args, opts)
+	if err == bstructs.ErrPluginShutdown && first {
+		first = false
+
+		h, err = l.refreshHandle()
+		if err == nil {
+			goto TRY
+		}
+	}
+
+	return out, stderr, c, err
+}
+
 func (l *LazyHandle) Stats(ctx context.Context, interval time.Duration) (<-chan *cstructs.TaskResourceUsage, error) {
 	h, err := l.getHandle()
 	if err != nil {
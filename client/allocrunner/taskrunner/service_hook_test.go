@@ -1,11 +1,18 @@
 package taskrunner
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
 	"github.com/hashicorp/nomad/client/taskenv"
+	agentconsul "github.com/hashicorp/nomad/command/agent/consul"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -91,3 +98,56 @@ func TestTaskRunner_ServiceHook_InterpolateServices(t *testing.T) {
 
 	require.Equal(t, exp, interpolated)
 }
+
+// TestTaskRunner_ServiceHook_ExportDefinitions asserts the check definitions
+// exported by the Consul service client are interpolated.
+func TestTaskRunner_ServiceHook_ExportDefinitions(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	logger := testlog.HCLogger(t)
+
+	consulClient := agentconsul.NewServiceClient(agentconsul.NewMockAgent(), logger, true)
+	go consulClient.Run()
+	defer consulClient.Shutdown()
+
+	alloc := mock.Alloc()
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+	task.Services = []*structs.Service{
+		{
+			Name:      "${NOMAD_TASK_NAME}",
+			PortLabel: "http",
+			Checks: []*structs.ServiceCheck{
+				{
+					Name:     "${NOMAD_TASK_NAME}-health",
+					Type:     "http",
+					Path:     "/health/${NOMAD_ALLOC_INDEX}",
+					Protocol: "http",
+					Interval: 10 * time.Second,
+					Timeout:  time.Second,
+				},
+			},
+		},
+	}
+
+	hook := newServiceHook(serviceHookConfig{
+		alloc:  alloc,
+		task:   task,
+		consul: consulClient,
+		logger: logger,
+	})
+	req := &interfaces.TaskPoststartRequest{
+		TaskEnv: taskenv.NewBuilder(mock.Node(), alloc, task, "global").Build(),
+	}
+	require.NoError(hook.Poststart(context.Background(), req, nil))
+
+	var defs []structs.ServiceCheck
+	testutil.WaitForResult(func() (bool, error) {
+		defs = consulClient.ExportDefinitions()
+		return len(defs) == 1, fmt.Errorf("expected 1 check definition; got %d", len(defs))
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	require.Equal("web-health", defs[0].Name)
+	require.Equal("/health/0", defs[0].Path)
+	require.Equal(10*time.Second, defs[0].Interval)
+}
@@ -64,6 +64,9 @@ type Agent struct {
 	// consulCatalog is the subset of Consul's Catalog API Nomad uses.
 	consulCatalog consul.CatalogAPI
 
+	// consulHealth is the subset of Consul's Health API Nomad uses.
+	consulHealth consul.HealthAPI
+
 	// client is the launched Nomad Client. Can be nil if the agent isn't
 	// configured to run a client.
 	client *client.Client
@@ -557,6 +560,7 @@ func (a *Agent) setupServer() error {
 	}
 
 	// Create the server
+	conf.ConsulHealth = a.consulHealth
 	server, err := nomad.NewServer(conf, a.consulCatalog)
 	if err != nil {
 		return fmt.Errorf("server setup failed: %v", err)
@@ -1022,12 +1026,39 @@ func (a *Agent) setupConsul(consulConfig *config.ConsulConfig) error {
 	// Create Consul Catalog client for service discovery.
 	a.consulCatalog = client.Catalog()
 
+	// Create Consul Health client for querying service health.
+	a.consulHealth = client.Health()
+
 	// Create Consul Service client for service advertisement and checks.
 	isClient := false
 	if a.config.Client != nil && a.config.Client.Enabled {
 		isClient = true
 	}
 	a.consulService = consul.NewServiceClient(client.Agent(), a.logger, isClient)
+	a.consulService.SetMaxChecksPerAlloc(consulConfig.MaxChecksPerAlloc)
+	a.consulService.SetShutdownCheckRetries(consulConfig.ShutdownCheckRetries)
+	a.consulService.SetCheckErrorSummaryInterval(consulConfig.CheckErrorSummaryInterval)
+	a.consulService.SetCheckWorkers(consulConfig.CheckWorkers)
+	a.consulService.SetCheckWebhookAllowlist(consulConfig.CheckWebhookAllowlist)
+	if consulConfig.CheckTracing != nil && *consulConfig.CheckTracing {
+		a.consulService.SetTracer(consul.NewLogTracer(a.logger.ResetNamed("consul")))
+	}
+	if err := a.consulService.SetCheckShutdownBehavior(consulConfig.CheckShutdownBehavior); err != nil {
+		return err
+	}
+
+	// Persist script check results so they survive restarts
+	if isClient && consulConfig.CheckCacheMaxAge > 0 {
+		stateDir := a.config.Client.StateDir
+		if stateDir == "" && a.config.DataDir != "" {
+			stateDir = filepath.Join(a.config.DataDir, "client")
+		}
+		if stateDir == "" {
+			a.logger.Warn("check result cache requires a data_dir or client state_dir; disabling")
+		} else if err := a.consulService.EnableCheckCache(filepath.Join(stateDir, "consul_checks.json"), consulConfig.CheckCacheMaxAge); err != nil {
+			return err
+		}
+	}
 
 	// Run the Consul service client's sync'ing main loop
 	go a.consulService.Run()
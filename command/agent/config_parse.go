@@ -778,16 +778,24 @@ func parseConsulConfig(result **config.ConsulConfig, list *ast.ObjectList) error
 		"auto_advertise",
 		"ca_file",
 		"cert_file",
+		"check_cache_max_age",
+		"check_error_summary_interval",
+		"check_shutdown_behavior",
+		"check_tracing",
+		"check_webhook_allowlist",
+		"check_workers",
 		"checks_use_advertise",
 		"client_auto_join",
 		"client_service_name",
 		"client_http_check_name",
 		"key_file",
+		"max_checks_per_alloc",
 		"server_auto_join",
 		"server_service_name",
 		"server_http_check_name",
 		"server_serf_check_name",
 		"server_rpc_check_name",
+		"shutdown_check_retries",
 		"ssl",
 		"timeout",
 		"token",
@@ -0,0 +1,163 @@
+package consul
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+)
+
+// checkCacheFlushInterval is how often changed check results are written to
+// disk. Results are also written when the ServiceClient shuts down.
+const checkCacheFlushInterval = 10 * time.Second
+
+// cachedCheckResult is the last result reported to Consul for a script check.
+type cachedCheckResult struct {
+	Status  string
+	Output  string
+	Updated time.Time
+}
+
+// checkResultCache persists the last reported result of each script check to
+// disk so the results can seed check registrations after an agent restart
+// instead of every check starting out critical.
+type checkResultCache struct {
+	path   string
+	maxAge time.Duration
+	logger log.Logger
+
+	// dirty is true if results changed since they were last written
+	results map[string]*cachedCheckResult
+	dirty   bool
+	mu      sync.Mutex
+}
+
+// newCheckResultCache loads the cache at path. A missing file results in an
+// empty cache. Results older than maxAge are ignored.
+func newCheckResultCache(path string, maxAge time.Duration, logger log.Logger) (*checkResultCache, error) {
+	c := &checkResultCache{
+		path:    path,
+		maxAge:  maxAge,
+		logger:  logger,
+		results: make(map[string]*cachedCheckResult),
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &c.results); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// get returns the cached result for a check if it hasn't expired.
+func (c *checkResultCache) get(checkID string) (*cachedCheckResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.results[checkID]
+	if !ok || time.Since(r.Updated) > c.maxAge {
+		return nil, false
+	}
+	return r, true
+}
+
+// set records a check's result. It is persisted by the next flush.
+func (c *checkResultCache) set(checkID, status, output string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results[checkID] = &cachedCheckResult{
+		Status:  status,
+		Output:  output,
+		Updated: time.Now(),
+	}
+	c.dirty = true
+}
+
+// run flushes the cache every checkCacheFlushInterval until shutdownCh is
+// closed. The final flush on shutdown is left to the caller so it can happen
+// after script checks have reported their last results.
+func (c *checkResultCache) run(shutdownCh <-chan struct{}) {
+	ticker := time.NewTicker(checkCacheFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.flush(); err != nil {
+				c.logger.Warn("failed to persist check results", "error", err)
+			}
+		case <-shutdownCh:
+			return
+		}
+	}
+}
+
+// flush persists the cache if any results changed since it was last written,
+// pruning any expired results.
+func (c *checkResultCache) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	now := time.Now()
+	for id, r := range c.results {
+		if now.Sub(r.Updated) > c.maxAge {
+			delete(c.results, id)
+		}
+	}
+
+	buf, err := json.Marshal(c.results)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash can't corrupt the cache
+	tmp := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// heartbeater wraps hb so every successful TTL update is recorded in the
+// cache. Updates are batched and written to disk when the cache is flushed.
+func (c *checkResultCache) heartbeater(hb heartbeater) heartbeater {
+	return &cachingHeartbeater{hb: hb, cache: c}
+}
+
+// cachingHeartbeater records successful TTL updates in a checkResultCache.
+type cachingHeartbeater struct {
+	hb    heartbeater
+	cache *checkResultCache
+}
+
+func (h *cachingHeartbeater) UpdateTTL(id, output, status string) error {
+	if err := h.hb.UpdateTTL(id, output, status); err != nil {
+		return err
+	}
+	h.cache.set(id, status, output)
+	return nil
+}
+
+func (h *cachingHeartbeater) CheckDeregister(id string) error {
+	return h.hb.CheckDeregister(id)
+}
@@ -0,0 +1,143 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConsul_CheckCache_Restart asserts a restarted ServiceClient registers
+// script checks with their cached status instead of critical.
+func TestConsul_CheckCache_Restart(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomadtest-checkcache")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checks.json")
+
+	checks := []*structs.ServiceCheck{
+		{
+			Name:     "scriptcheck",
+			Type:     "script",
+			Interval: 9000 * time.Hour,
+			Timeout:  9000 * time.Hour,
+		},
+	}
+
+	// Run the check once so its passing result is cached
+	ctx := setupFake(t)
+	require.NoError(ctx.ServiceClient.EnableCheckCache(path, time.Hour))
+	ctx.Task.Services[0].Checks = checks
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	testutil.WaitForResult(func() (bool, error) {
+		if err := ctx.ServiceClient.checkCache.flush(); err != nil {
+			return false, err
+		}
+		cache, err := newCheckResultCache(path, time.Hour, testlog.HCLogger(t))
+		if err != nil {
+			return false, err
+		}
+		if len(cache.results) != 1 {
+			return false, fmt.Errorf("expected 1 cached result; got %d", len(cache.results))
+		}
+		for _, r := range cache.results {
+			if r.Status != api.HealthPassing {
+				return false, fmt.Errorf("expected passing; got %q", r.Status)
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	for _, h := range ctx.ServiceClient.runningScripts {
+		h.cancel()
+	}
+
+	// restart registers the same task with a new ServiceClient whose script
+	// never completes, so only the cached status can be reported
+	restart := func(maxAge time.Duration) *api.AgentCheckRegistration {
+		ctx2 := setupFake(t)
+		require.NoError(ctx2.ServiceClient.EnableCheckCache(path, maxAge))
+		ctx2.MockExec.ExecFunc = func(ctx context.Context, _ string, _ []string) ([]byte, int, error) {
+			<-ctx.Done()
+			return nil, 0, ctx.Err()
+		}
+		ctx2.Task.AllocID = ctx.Task.AllocID
+		ctx2.Task.Services[0].Checks = checks
+		require.NoError(ctx2.ServiceClient.RegisterTask(ctx2.Task))
+		require.NoError(ctx2.syncOnce())
+		defer func() {
+			for _, h := range ctx2.ServiceClient.runningScripts {
+				h.cancel()
+			}
+		}()
+
+		regs := ctx2.FakeConsul.CheckRegs()
+		require.Len(regs, 1)
+		return regs[0]
+	}
+
+	require.Equal(api.HealthPassing, restart(time.Hour).Status)
+
+	// Expired results are ignored
+	require.Empty(restart(time.Nanosecond).Status)
+}
+
+// TestConsul_CheckCache_Flush asserts check results are batched in memory and
+// only written to disk when the cache is flushed, including on shutdown.
+func TestConsul_CheckCache_Flush(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "nomadtest-checkcache")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checks.json")
+
+	ctx := setupFake(t)
+	require.NoError(ctx.ServiceClient.EnableCheckCache(path, time.Hour))
+	hb := ctx.ServiceClient.checkCache.heartbeater(&fakeHeartbeater{updates: make(chan execStatus, 20)})
+
+	// Updates aren't written until flushed
+	for i := 0; i < 10; i++ {
+		require.NoError(hb.UpdateTTL("check1", fmt.Sprintf("run %d", i), api.HealthPassing))
+	}
+	_, err = os.Stat(path)
+	require.True(os.IsNotExist(err), "expected no cache file; got %v", err)
+
+	require.NoError(ctx.ServiceClient.checkCache.flush())
+	cache, err := newCheckResultCache(path, time.Hour, testlog.HCLogger(t))
+	require.NoError(err)
+	r, ok := cache.get("check1")
+	require.True(ok)
+	require.Equal("run 9", r.Output)
+
+	// Flushing without changes doesn't rewrite the file
+	require.NoError(os.Remove(path))
+	require.NoError(ctx.ServiceClient.checkCache.flush())
+	_, err = os.Stat(path)
+	require.True(os.IsNotExist(err), "expected no cache file; got %v", err)
+
+	// Shutting down writes pending results
+	require.NoError(hb.UpdateTTL("check2", "ok", api.HealthWarning))
+	require.NoError(ctx.ServiceClient.Shutdown())
+	cache, err = newCheckResultCache(path, time.Hour, testlog.HCLogger(t))
+	require.NoError(err)
+	r, ok = cache.get("check2")
+	require.True(ok)
+	require.Equal(api.HealthWarning, r.Status)
+}
@@ -0,0 +1,106 @@
+package consul
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// checkQueuePerWorker is how many script check runs may wait in the queue of a
+// checkPool per worker.
+const checkQueuePerWorker = 8
+
+// checkJob is a script check run waiting for a free worker.
+type checkJob struct {
+	fn       func()
+	enqueued time.Time
+}
+
+// checkPool runs script checks on a fixed number of workers so the total
+// overhead of script checks is bounded. Runs that are due while every worker
+// is busy wait in a bounded queue.
+type checkPool struct {
+	workers int
+	queue   chan *checkJob
+
+	// active is the number of runs being executed by workers. Accessed with
+	// atomics.
+	active int32
+
+	shutdownCh <-chan struct{}
+}
+
+// newCheckPool creates a checkPool with the given number of workers and queue
+// size. start must be called to start the workers.
+func newCheckPool(workers, queueSize int, shutdownCh <-chan struct{}) *checkPool {
+	return &checkPool{
+		workers:    workers,
+		queue:      make(chan *checkJob, queueSize),
+		shutdownCh: shutdownCh,
+	}
+}
+
+// start the workers. They exit when shutdownCh is closed.
+func (p *checkPool) start() {
+	for i := 0; i < p.workers; i++ {
+		go p.work()
+	}
+}
+
+// work runs queued jobs until shutdown.
+func (p *checkPool) work() {
+	for {
+		select {
+		case <-p.shutdownCh:
+			return
+		case job := <-p.queue:
+			metrics.SetGauge([]string{"client", "consul", "script_queue_depth"}, float32(len(p.queue)))
+			metrics.MeasureSince([]string{"client", "consul", "script_queue_wait"}, job.enqueued)
+
+			atomic.AddInt32(&p.active, 1)
+			job.fn()
+			atomic.AddInt32(&p.active, -1)
+		}
+	}
+}
+
+// submit queues fn to be run by a worker, waiting at most wait for room in the
+// queue. False is returned if fn wasn't queued because the queue stayed full,
+// ctx was cancelled, or the pool is shutting down.
+func (p *checkPool) submit(ctx context.Context, wait time.Duration, fn func()) bool {
+	job := &checkJob{
+		fn:       fn,
+		enqueued: time.Now(),
+	}
+
+	select {
+	case p.queue <- job:
+	default:
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case p.queue <- job:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-p.shutdownCh:
+			return false
+		}
+	}
+
+	metrics.SetGauge([]string{"client", "consul", "script_queue_depth"}, float32(len(p.queue)))
+	return true
+}
+
+// depth returns how many runs are waiting for a free worker.
+func (p *checkPool) depth() int {
+	return len(p.queue)
+}
+
+// running returns how many runs are being executed by workers.
+func (p *checkPool) running() int {
+	return int(atomic.LoadInt32(&p.active))
+}
@@ -0,0 +1,144 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/testlog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckPool asserts a checkPool caps how many jobs run at once, queues
+// the rest, and rejects jobs once its queue stays full.
+func TestCheckPool(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	shutdownCh := make(chan struct{})
+	defer close(shutdownCh)
+	pool := newCheckPool(2, 3, shutdownCh)
+	pool.start()
+
+	var running, maxRunning int32
+	release := make(chan struct{})
+	done := make(chan struct{}, 5)
+	job := func() {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		done <- struct{}{}
+	}
+
+	for i := 0; i < 5; i++ {
+		require.True(pool.submit(context.Background(), time.Second, job))
+	}
+	testutil.WaitForResult(func() (bool, error) {
+		if n := pool.running(); n != 2 {
+			return false, fmt.Errorf("expected 2 running; got %d", n)
+		}
+		return pool.depth() == 3, fmt.Errorf("expected 3 queued; got %d", pool.depth())
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The queue is full so further jobs are rejected once the wait elapses
+	start := time.Now()
+	require.False(pool.submit(context.Background(), 50*time.Millisecond, job))
+	require.True(time.Since(start) >= 50*time.Millisecond)
+
+	close(release)
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for jobs to run")
+		}
+	}
+	require.Equal(int32(2), atomic.LoadInt32(&maxRunning))
+	require.Zero(pool.depth())
+}
+
+// blockingExec is a ScriptExecutor that blocks until released, tracking how
+// many executions run at once.
+type blockingExec struct {
+	running    *int32
+	maxRunning *int32
+	release    chan struct{}
+}
+
+func (b blockingExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	n := atomic.AddInt32(b.running, 1)
+	defer atomic.AddInt32(b.running, -1)
+	for {
+		max := atomic.LoadInt32(b.maxRunning)
+		if n <= max || atomic.CompareAndSwapInt32(b.maxRunning, max, n) {
+			break
+		}
+	}
+	<-b.release
+	return []byte("ok"), 0, nil
+}
+
+// TestConsulScript_Pool asserts script checks sharing a worker pool run at
+// most as many at once as there are workers, with the rest queued.
+func TestConsulScript_Pool(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	shutdownCh := make(chan struct{})
+	defer close(shutdownCh)
+	pool := newCheckPool(2, 16, shutdownCh)
+	pool.start()
+
+	var running, maxRunning int32
+	exec := blockingExec{
+		running:    &running,
+		maxRunning: &maxRunning,
+		release:    make(chan struct{}),
+	}
+
+	hb := newFakeHeartbeater()
+	for i := 0; i < 6; i++ {
+		serviceCheck := structs.ServiceCheck{
+			Name:     fmt.Sprintf("slow-%d", i),
+			Interval: time.Hour,
+			Timeout:  time.Minute,
+		}
+		check := newScriptCheck("allocid", "testtask", fmt.Sprintf("checkid-%d", i), &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+		check.pool = pool
+		handle := check.run()
+		defer handle.cancel()
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		if n := atomic.LoadInt32(&running); n != 2 {
+			return false, fmt.Errorf("expected 2 running checks; got %d", n)
+		}
+		return pool.depth() == 4, fmt.Errorf("expected 4 queued checks; got %d", pool.depth())
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	close(exec.release)
+	for i := 0; i < 6; i++ {
+		select {
+		case update := <-hb.updates:
+			require.Equal("ok", update.output)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for script checks to exec")
+		}
+	}
+	require.Equal(int32(2), atomic.LoadInt32(&maxRunning))
+	require.Zero(pool.depth())
+}
@@ -3,8 +3,10 @@ package consul
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/nomad/helper"
+	"github.com/hashicorp/nomad/helper/lockedrand"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/plugins/drivers"
 )
@@ -51,6 +54,15 @@ const (
 	// enqueued operations to sync to Consul by default.
 	defaultShutdownWait = time.Minute
 
+	// retryJitterFraction is the fraction of a retry backoff that is
+	// randomly shaved off so retries from many agents don't line up. A value
+	// of 4 shortens backoffs by up to a quarter.
+	retryJitterFraction = 4
+
+	// defaultCheckStartStagger is how long after the script checks of one
+	// StartOrder the checks of the next StartOrder are started.
+	defaultCheckStartStagger = 100 * time.Millisecond
+
 	// DefaultQueryWaitDuration is the max duration the Consul Agent will
 	// spend waiting for a response from a Consul Query.
 	DefaultQueryWaitDuration = 2 * time.Second
@@ -71,6 +83,11 @@ type CatalogAPI interface {
 	Service(service, tag string, q *api.QueryOptions) ([]*api.CatalogService, *api.QueryMeta, error)
 }
 
+// HealthAPI is the consul/api.Health API used by Nomad.
+type HealthAPI interface {
+	Checks(service string, q *api.QueryOptions) (api.HealthChecks, *api.QueryMeta, error)
+}
+
 // AgentAPI is the consul/api.Agent API used by Nomad.
 type AgentAPI interface {
 	Services() (map[string]*api.AgentService, error)
@@ -90,6 +107,9 @@ type operations struct {
 	regChecks   []*api.AgentCheckRegistration
 	scripts     []*scriptCheck
 
+	// checkDefs are the registered check definitions keyed by check ID
+	checkDefs map[string]*structs.ServiceCheck
+
 	deregServices []string
 	deregChecks   []string
 }
@@ -191,6 +211,32 @@ func (s *ServiceRegistration) copy() *ServiceRegistration {
 	}
 }
 
+// CheckExport is the definition of a registered task check.
+type CheckExport struct {
+	// ID is the ID of the check in Consul
+	ID string
+
+	// AllocID and TaskName identify the task that registered the check
+	AllocID  string
+	TaskName string
+
+	// Check is the registered definition of the check
+	Check structs.ServiceCheck
+}
+
+// MaintenanceInfo describes a script check in maintenance.
+type MaintenanceInfo struct {
+	// CheckID is the ID of the check in Consul
+	CheckID string
+
+	// Status and Reason are reported for the check instead of running it
+	Status string
+	Reason string
+
+	// Since is when the check entered maintenance
+	Since time.Time
+}
+
 // ServiceClient handles task and agent service registration with Consul.
 type ServiceClient struct {
 	client           AgentAPI
@@ -216,6 +262,16 @@ type ServiceClient struct {
 	scripts        map[string]*scriptCheck
 	runningScripts map[string]*scriptHandle
 
+	// dedupedScripts are the running script checks shared by identical
+	// deduplicated checks, keyed by dedupeKey. dedupeKeys maps the ID of
+	// every deduplicated check to its key.
+	dedupedScripts map[string]*scriptCheck
+	dedupeKeys     map[string]string
+
+	// scriptsLock guards modifying scripts and the dedupe maps so they can
+	// be read outside of Run. Run doesn't need to lock to read them.
+	scriptsLock sync.RWMutex
+
 	// allocRegistrations stores the services and checks that are registered
 	// with Consul by allocation ID.
 	allocRegistrations     map[string]*AllocRegistration
@@ -237,6 +293,51 @@ type ServiceClient struct {
 	// isClientAgent specifies whether this Consul client is being used
 	// by a Nomad client.
 	isClientAgent bool
+
+	// tracer is passed to script checks to trace their executions. Tracing
+	// is disabled if nil.
+	tracer Tracer
+
+	// rand jitters the sync retry backoff and is shared with script checks
+	// to jitter their retries.
+	rand *lockedrand.Rand
+
+	// checkCache persists script check results across agent restarts. It is
+	// nil unless enabled with EnableCheckCache.
+	checkCache *checkResultCache
+
+	// checkDefs are the definitions of the registered task checks
+	// keyed by check ID.
+	checkDefs     map[string]*structs.ServiceCheck
+	checkDefsLock sync.RWMutex
+
+	// maxChecksPerAlloc is the maximum number of checks an allocation may
+	// register. Zero means unlimited.
+	maxChecksPerAlloc int
+
+	// shutdownCheckRetries is how many times script checks retry a failed
+	// final run on shutdown before reporting it.
+	shutdownCheckRetries int
+
+	// checkShutdownBehavior is what script checks do on shutdown. If empty
+	// they are drained.
+	checkShutdownBehavior string
+
+	// checkErrorSummaryInterval is how often script checks summarize
+	// repeated errors updating Consul. If zero the default is used.
+	checkErrorSummaryInterval time.Duration
+
+	// checkWebhookAllowlist is the hosts script check webhooks may be sent
+	// to. If empty webhooks aren't sent.
+	checkWebhookAllowlist []string
+
+	// checkPool runs script checks on a fixed number of workers. It is nil
+	// if script checks run as soon as they are due.
+	checkPool *checkPool
+
+	// checkStartStagger is how long after the script checks of one
+	// StartOrder the checks of the next StartOrder are started.
+	checkStartStagger time.Duration
 }
 
 // NewServiceClient creates a new Consul ServiceClient from an existing Consul API
@@ -259,11 +360,16 @@ func NewServiceClient(consulClient AgentAPI, logger log.Logger, isNomadClient bo
 		checks:             make(map[string]*api.AgentCheckRegistration),
 		scripts:            make(map[string]*scriptCheck),
 		runningScripts:     make(map[string]*scriptHandle),
+		dedupedScripts:     make(map[string]*scriptCheck),
+		dedupeKeys:         make(map[string]string),
 		allocRegistrations: make(map[string]*AllocRegistration),
 		agentServices:      make(map[string]struct{}),
 		agentChecks:        make(map[string]struct{}),
 		checkWatcher:       newCheckWatcher(logger, consulClient),
 		isClientAgent:      isNomadClient,
+		checkStartStagger:  defaultCheckStartStagger,
+		rand:               lockedrand.New(nil),
+		checkDefs:          make(map[string]*structs.ServiceCheck),
 	}
 }
 
@@ -282,6 +388,20 @@ func (c *ServiceClient) hasSeen() bool {
 	return atomic.LoadInt32(&c.seen) == seen
 }
 
+// EnableCheckCache persists the last result of each script check to path and
+// uses results younger than maxAge as the initial status of script checks
+// registered after a restart. Results are written periodically and on
+// Shutdown. It must be called before Run.
+func (c *ServiceClient) EnableCheckCache(path string, maxAge time.Duration) error {
+	cache, err := newCheckResultCache(path, maxAge, c.logger)
+	if err != nil {
+		return fmt.Errorf("failed to load check result cache: %v", err)
+	}
+	c.checkCache = cache
+	go cache.run(c.shutdownCh)
+	return nil
+}
+
 // Run the Consul main loop which retries operations against Consul. It should
 // be called exactly once.
 func (c *ServiceClient) Run() {
@@ -347,11 +467,7 @@ INIT:
 				default:
 				}
 			}
-			backoff := c.retryInterval * time.Duration(failures)
-			if backoff > c.maxRetryInterval {
-				backoff = c.maxRetryInterval
-			}
-			retryTimer.Reset(backoff)
+			retryTimer.Reset(c.retryBackoff(failures))
 		} else {
 			if failures > 0 {
 				c.logger.Info("successfully updated services in Consul")
@@ -401,20 +517,30 @@ func (c *ServiceClient) merge(ops *operations) {
 	for _, check := range ops.regChecks {
 		c.checks[check.ID] = check
 	}
+	c.scriptsLock.Lock()
 	for _, s := range ops.scripts {
 		c.scripts[s.id] = s
 	}
+	for _, cid := range ops.deregChecks {
+		c.stopScript(cid)
+	}
+	c.scriptsLock.Unlock()
 	for _, sid := range ops.deregServices {
 		delete(c.services, sid)
 	}
 	for _, cid := range ops.deregChecks {
-		if script, ok := c.runningScripts[cid]; ok {
-			script.cancel()
-			delete(c.scripts, cid)
-			delete(c.runningScripts, cid)
-		}
 		delete(c.checks, cid)
 	}
+
+	c.checkDefsLock.Lock()
+	for id, def := range ops.checkDefs {
+		c.checkDefs[id] = def
+	}
+	for _, cid := range ops.deregChecks {
+		delete(c.checkDefs, cid)
+	}
+	c.checkDefsLock.Unlock()
+
 	metrics.SetGauge([]string{"client", "consul", "services"}, float32(len(c.services)))
 	metrics.SetGauge([]string{"client", "consul", "checks"}, float32(len(c.checks)))
 	metrics.SetGauge([]string{"client", "consul", "script_checks"}, float32(len(c.runningScripts)))
@@ -508,6 +634,13 @@ func (c *ServiceClient) sync() error {
 		metrics.IncrCounter([]string{"client", "consul", "check_deregistrations"}, 1)
 	}
 
+	// Start the scripts of the checks registered below once done, even if
+	// registering others fails
+	var scripts []*scriptCheck
+	defer func() {
+		c.startScripts(scripts)
+	}()
+
 	// Add Nomad checks missing from Consul
 	for id, check := range c.checks {
 		if _, ok := consulChecks[id]; ok {
@@ -524,12 +657,7 @@ func (c *ServiceClient) sync() error {
 
 		// Handle starting scripts
 		if script, ok := c.scripts[id]; ok {
-			// If it's already running, cancel and replace
-			if oldScript, running := c.runningScripts[id]; running {
-				oldScript.cancel()
-			}
-			// Start and store the handle
-			c.runningScripts[id] = script.run()
+			scripts = append(scripts, script)
 		}
 	}
 
@@ -541,6 +669,160 @@ func (c *ServiceClient) sync() error {
 	return nil
 }
 
+// dedupeKey returns the key identical deduplicated script checks of an
+// allocation share.
+//
+// The task isn't part of the key since sharing runs across tasks is the point
+// of deduplicating. The shared script runs in the environment of whichever
+// task's check runs it, so its result is reported for the other tasks even
+// though they may have a different filesystem or environment. Checks whose
+// command or arguments are interpolated with task specific values differ
+// after interpolation and so aren't shared.
+func dedupeKey(script *scriptCheck) string {
+	return script.allocID + "/" + script.check.Hash("")
+}
+
+// startScripts starts script checks in ascending StartOrder. The checks of each
+// successive StartOrder are delayed by another checkStartStagger so the checks
+// others depend on start first and fewer processes are spawned at once, such
+// as when many checks are restored. Checks sharing a StartOrder start together.
+func (c *ServiceClient) startScripts(scripts []*scriptCheck) {
+	if len(scripts) == 0 {
+		return
+	}
+
+	sort.Slice(scripts, func(i, j int) bool {
+		if scripts[i].check.StartOrder != scripts[j].check.StartOrder {
+			return scripts[i].check.StartOrder < scripts[j].check.StartOrder
+		}
+		return scripts[i].id < scripts[j].id
+	})
+
+	c.scriptsLock.Lock()
+	defer c.scriptsLock.Unlock()
+	var stagger time.Duration
+	for i, script := range scripts {
+		if i > 0 && script.check.StartOrder != scripts[i-1].check.StartOrder {
+			stagger += c.checkStartStagger
+		}
+		script.startDelay = stagger
+		c.startScript(script)
+	}
+}
+
+// startScript runs a script check, replacing it if it's already running. A
+// deduplicated check identical to one already running isn't run; the running
+// check updates it with its results instead. scriptsLock must be held.
+func (c *ServiceClient) startScript(script *scriptCheck) {
+	if script.check.Dedupe {
+		key := dedupeKey(script)
+		c.dedupeKeys[script.id] = key
+		if shared, ok := c.dedupedScripts[key]; ok {
+			if shared.id != script.id {
+				shared.addSharedID(script.id)
+				return
+			}
+
+			// Replacing the shared check; keep updating its followers
+			for _, id := range shared.sharedCheckIDs() {
+				script.addSharedID(id)
+			}
+		}
+		c.dedupedScripts[key] = script
+	}
+
+	// If it's already running, cancel and replace
+	if oldScript, running := c.runningScripts[script.id]; running {
+		oldScript.cancel()
+	}
+	// Start and store the handle
+	c.runningScripts[script.id] = script.run()
+}
+
+// stopScript stops the script check with the given ID if it's running. If
+// other deduplicated checks shared its run, one of them takes over running
+// the script. scriptsLock must be held.
+func (c *ServiceClient) stopScript(cid string) {
+	var followers []string
+	if key, ok := c.dedupeKeys[cid]; ok {
+		delete(c.dedupeKeys, cid)
+		if shared, ok := c.dedupedScripts[key]; ok {
+			if shared.id != cid {
+				shared.removeSharedID(cid)
+				delete(c.scripts, cid)
+				return
+			}
+			delete(c.dedupedScripts, key)
+			followers = shared.sharedCheckIDs()
+		}
+	}
+
+	if script, ok := c.runningScripts[cid]; ok {
+		script.cancel()
+		delete(c.scripts, cid)
+		delete(c.runningScripts, cid)
+	}
+
+	// Promote the first remaining follower to run the shared script
+	for _, id := range followers {
+		if script, ok := c.scripts[id]; ok {
+			script.startDelay = 0
+			c.startScript(script)
+		}
+	}
+}
+
+// HasRun returns true if the script check with the given ID has been executed
+// at least once. Deduplicated checks have run once the check they share has.
+// False is returned for unknown checks, including checks run by Consul.
+func (c *ServiceClient) HasRun(checkID string) bool {
+	c.scriptsLock.RLock()
+	defer c.scriptsLock.RUnlock()
+
+	script, ok := c.scripts[checkID]
+	if !ok {
+		return false
+	}
+	if key, ok := c.dedupeKeys[checkID]; ok {
+		if shared, ok := c.dedupedScripts[key]; ok {
+			script = shared
+		}
+	}
+	return script.hasRun()
+}
+
+// ChecksInMaintenance returns the script checks currently in maintenance,
+// sorted by check ID. Deduplicated checks are in maintenance along with the
+// check running on their behalf.
+func (c *ServiceClient) ChecksInMaintenance() []MaintenanceInfo {
+	c.scriptsLock.RLock()
+	defer c.scriptsLock.RUnlock()
+
+	var checks []MaintenanceInfo
+	for checkID, script := range c.scripts {
+		if key, ok := c.dedupeKeys[checkID]; ok {
+			if shared, ok := c.dedupedScripts[key]; ok {
+				script = shared
+			}
+		}
+
+		maint := script.maintenance()
+		if !maint.enabled {
+			continue
+		}
+		checks = append(checks, MaintenanceInfo{
+			CheckID: checkID,
+			Status:  maint.status,
+			Reason:  maint.reason,
+			Since:   maint.since,
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].CheckID < checks[j].CheckID
+	})
+	return checks
+}
+
 // RegisterAgent registers Nomad agents (client or server). The
 // Service.PortLabel should be a literal port to be parsed with SplitHostPort.
 // Script checks are not supported and will return an error. Registration is
@@ -700,13 +982,35 @@ func (c *ServiceClient) checkRegs(ops *operations, serviceID string, service *st
 	for _, check := range service.Checks {
 		checkID := makeCheckID(serviceID, check)
 		checkIDs = append(checkIDs, checkID)
+
+		if ops.checkDefs == nil {
+			ops.checkDefs = make(map[string]*structs.ServiceCheck, numChecks)
+		}
+		ops.checkDefs[checkID] = check.Copy()
+
 		if check.Type == structs.ServiceCheckScript {
 			if task.DriverExec == nil {
 				return nil, fmt.Errorf("driver doesn't support script checks")
 			}
 
+			var agent heartbeater = c.client
+			if c.checkCache != nil {
+				agent = c.checkCache.heartbeater(c.client)
+			}
 			sc := newScriptCheck(task.AllocID, task.Name, checkID, check, task.DriverExec,
-				c.client, c.logger, c.shutdownCh)
+				agent, c.tracer, c.logger, c.shutdownCh)
+			sc.shutdownRetries = c.shutdownCheckRetries
+			sc.shutdownBehavior = c.checkShutdownBehavior
+			if c.checkErrorSummaryInterval > 0 {
+				sc.errSummaryInterval = c.checkErrorSummaryInterval
+			}
+			sc.pool = c.checkPool
+			sc.rand = c.rand
+			if check.Webhook != "" && !c.webhookAllowed(check.Webhook) {
+				c.logger.Warn("check webhook host isn't in the agent's check_webhook_allowlist; not sending webhooks",
+					"alloc_id", task.AllocID, "task", task.Name, "check", check.Name, "webhook", check.Webhook)
+				sc.webhook = ""
+			}
 			ops.scripts = append(ops.scripts, sc)
 
 			// Skip getAddress for script checks
@@ -714,6 +1018,14 @@ func (c *ServiceClient) checkRegs(ops *operations, serviceID string, service *st
 			if err != nil {
 				return nil, fmt.Errorf("failed to add script check %q: %v", check.Name, err)
 			}
+
+			// Seed the initial status from the last known result
+			if c.checkCache != nil {
+				if r, ok := c.checkCache.get(checkID); ok {
+					checkReg.Status = r.Status
+					sc.lastState = r.Status
+				}
+			}
 			ops.regChecks = append(ops.regChecks, checkReg)
 			continue
 		}
@@ -745,6 +1057,203 @@ func (c *ServiceClient) checkRegs(ops *operations, serviceID string, service *st
 	return checkIDs, nil
 }
 
+// SetMaxChecksPerAlloc limits the number of checks a single allocation may
+// register. Registrations exceeding the limit are rejected. Zero means
+// unlimited. It must be called before Run.
+func (c *ServiceClient) SetMaxChecksPerAlloc(max int) {
+	c.maxChecksPerAlloc = max
+}
+
+// SetShutdownCheckRetries sets how many times script checks retry a failed
+// final run on shutdown before reporting its status. It must be called before
+// Run.
+func (c *ServiceClient) SetShutdownCheckRetries(retries int) {
+	c.shutdownCheckRetries = retries
+}
+
+// SetCheckShutdownBehavior sets what script checks do when the agent shuts
+// down: run once more and report their status (drain), deregister from Consul
+// (deregister), or nothing (leave). An empty behavior drains. It must be
+// called before Run.
+func (c *ServiceClient) SetCheckShutdownBehavior(behavior string) error {
+	switch behavior {
+	case "", CheckShutdownDrain, CheckShutdownDeregister, CheckShutdownLeave:
+	default:
+		return fmt.Errorf("invalid check shutdown behavior %q: must be one of %q, %q, or %q",
+			behavior, CheckShutdownDrain, CheckShutdownDeregister, CheckShutdownLeave)
+	}
+	c.checkShutdownBehavior = behavior
+	return nil
+}
+
+// SetCheckErrorSummaryInterval sets how often script checks summarize
+// repeated identical errors updating Consul instead of logging each one. A
+// non-positive interval uses the default. It must be called before Run.
+func (c *ServiceClient) SetCheckErrorSummaryInterval(interval time.Duration) {
+	c.checkErrorSummaryInterval = interval
+}
+
+// SetCheckWebhookAllowlist sets the hosts, optionally with a port, script
+// check webhooks may be sent to. "*" allows any host. Webhooks of checks whose
+// host isn't allowed aren't sent, and none are sent if the allowlist is empty.
+// It must be called before Run.
+func (c *ServiceClient) SetCheckWebhookAllowlist(hosts []string) {
+	c.checkWebhookAllowlist = hosts
+}
+
+// webhookAllowed returns whether the host of the given webhook URL is in the
+// check webhook allowlist.
+func (c *ServiceClient) webhookAllowed(webhook string) bool {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return false
+	}
+	for _, host := range c.checkWebhookAllowlist {
+		if host == "*" || strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCheckWorkers limits how many script checks run at once to the given
+// number of workers. A non-positive number runs every check as soon as it is
+// due. It must be called before Run.
+func (c *ServiceClient) SetCheckWorkers(workers int) {
+	if workers <= 0 {
+		c.checkPool = nil
+		return
+	}
+	c.checkPool = newCheckPool(workers, workers*checkQueuePerWorker, c.shutdownCh)
+	c.checkPool.start()
+}
+
+// SetTracer sets the Tracer used to trace the executions and heartbeats of
+// script checks. A nil tracer disables tracing. It must be called before Run.
+func (c *ServiceClient) SetTracer(tracer Tracer) {
+	c.tracer = tracer
+}
+
+// SetRandSource sets the source of randomness used to jitter retries of
+// syncing with Consul and of script checks. A fixed source makes the jitter
+// reproducible in tests. A nil source uses one seeded with the current time.
+// It must be called before Run.
+func (c *ServiceClient) SetRandSource(src rand.Source) {
+	c.rand = lockedrand.New(src)
+}
+
+// retryBackoff returns how long to wait before retrying a sync with Consul
+// after the given number of consecutive failures.
+func (c *ServiceClient) retryBackoff(failures int) time.Duration {
+	backoff := c.retryInterval * time.Duration(failures)
+	if backoff > c.maxRetryInterval {
+		backoff = c.maxRetryInterval
+	}
+	return jitter(c.rand, backoff)
+}
+
+// CheckQueueDepth returns how many script check runs are waiting for a free
+// worker. It is always zero if the number of workers isn't limited.
+func (c *ServiceClient) CheckQueueDepth() int {
+	if c.checkPool == nil {
+		return 0
+	}
+	return c.checkPool.depth()
+}
+
+// checkAllocCheckLimit returns an error if registering the task's checks
+// would exceed the number of checks allowed for its allocation. Checks
+// already registered by the task are replaced and so are not counted.
+func (c *ServiceClient) checkAllocCheckLimit(task *TaskServices) error {
+	if c.maxChecksPerAlloc <= 0 {
+		return nil
+	}
+
+	total := 0
+	for _, service := range task.Services {
+		total += len(service.Checks)
+	}
+
+	c.allocRegistrationsLock.RLock()
+	if alloc, ok := c.allocRegistrations[task.AllocID]; ok {
+		for name, treg := range alloc.Tasks {
+			if name == task.Name {
+				continue
+			}
+			for _, sreg := range treg.Services {
+				total += len(sreg.checkIDs)
+			}
+		}
+	}
+	c.allocRegistrationsLock.RUnlock()
+
+	if total > c.maxChecksPerAlloc {
+		c.logger.Error("rejecting check registrations exceeding allocation limit",
+			"alloc_id", task.AllocID, "task", task.Name, "checks", total, "limit", c.maxChecksPerAlloc)
+		return fmt.Errorf("allocation %q would have %d checks which exceeds the limit of %d",
+			task.AllocID, total, c.maxChecksPerAlloc)
+	}
+	return nil
+}
+
+// ExportDefinitions returns the definitions of the task checks currently
+// registered, after interpolation by the task runner, so they may be compared
+// with or pasted back into a job.
+func (c *ServiceClient) ExportDefinitions() []structs.ServiceCheck {
+	c.checkDefsLock.RLock()
+	defer c.checkDefsLock.RUnlock()
+
+	defs := make([]structs.ServiceCheck, 0, len(c.checkDefs))
+	for _, def := range c.checkDefs {
+		defs = append(defs, *def.Copy())
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// ChecksForTask returns the definitions of the checks registered by a
+// task, sorted by name.
+func (c *ServiceClient) ChecksForTask(allocID, task string) []CheckExport {
+	c.allocRegistrationsLock.RLock()
+	var checkIDs []string
+	if areg, ok := c.allocRegistrations[allocID]; ok {
+		if treg, ok := areg.Tasks[task]; ok {
+			for _, sreg := range treg.Services {
+				for id := range sreg.checkIDs {
+					checkIDs = append(checkIDs, id)
+				}
+			}
+		}
+	}
+	c.allocRegistrationsLock.RUnlock()
+
+	c.checkDefsLock.RLock()
+	defer c.checkDefsLock.RUnlock()
+	checks := make([]CheckExport, 0, len(checkIDs))
+	for _, id := range checkIDs {
+		def, ok := c.checkDefs[id]
+		if !ok {
+			// Not yet committed
+			continue
+		}
+		checks = append(checks, CheckExport{
+			ID:       id,
+			AllocID:  allocID,
+			TaskName: task,
+			Check:    *def.Copy(),
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Check.Name != checks[j].Check.Name {
+			return checks[i].Check.Name < checks[j].Check.Name
+		}
+		return checks[i].ID < checks[j].ID
+	})
+	return checks
+}
+
 // RegisterTask with Consul. Adds all service entries and checks to Consul. If
 // exec is nil and a script check exists an error is returned.
 //
@@ -759,6 +1268,10 @@ func (c *ServiceClient) RegisterTask(task *TaskServices) error {
 		return nil
 	}
 
+	if err := c.checkAllocCheckLimit(task); err != nil {
+		return err
+	}
+
 	t := new(TaskRegistration)
 	t.Services = make(map[string]*ServiceRegistration, numServices)
 
@@ -791,10 +1304,14 @@ func (c *ServiceClient) RegisterTask(task *TaskServices) error {
 }
 
 // UpdateTask in Consul. Does not alter the service if only checks have
-// changed.
+// changed, and only replaces the checks that were added, changed, or removed.
 //
 // DriverNetwork must not change between invocations for the same allocation.
 func (c *ServiceClient) UpdateTask(old, newTask *TaskServices) error {
+	if err := c.checkAllocCheckLimit(newTask); err != nil {
+		return err
+	}
+
 	ops := &operations{}
 
 	taskReg := new(TaskRegistration)
@@ -838,46 +1355,9 @@ func (c *ServiceClient) UpdateTask(old, newTask *TaskServices) error {
 		}
 		taskReg.Services[existingID] = sreg
 
-		// See if any checks were updated
-		existingChecks := make(map[string]*structs.ServiceCheck, len(existingSvc.Checks))
-		for _, check := range existingSvc.Checks {
-			existingChecks[makeCheckID(existingID, check)] = check
-		}
-
-		// Register new checks
-		for _, check := range newSvc.Checks {
-			checkID := makeCheckID(existingID, check)
-			if _, exists := existingChecks[checkID]; exists {
-				// Check exists, so don't remove it
-				delete(existingChecks, checkID)
-				sreg.checkIDs[checkID] = struct{}{}
-			}
-
-			// New check on an unchanged service; add them now
-			newCheckIDs, err := c.checkRegs(ops, existingID, newSvc, newTask)
-			if err != nil {
-				return err
-			}
-
-			for _, checkID := range newCheckIDs {
-				sreg.checkIDs[checkID] = struct{}{}
-
-			}
-
-			// Update all watched checks as CheckRestart fields aren't part of ID
-			if check.TriggersRestarts() {
-				c.checkWatcher.Watch(newTask.AllocID, newTask.Name, checkID, check, newTask.Restarter)
-			}
-		}
-
-		// Remove existing checks not in updated service
-		for cid, check := range existingChecks {
-			ops.deregChecks = append(ops.deregChecks, cid)
-
-			// Unwatch checks
-			if check.TriggersRestarts() {
-				c.checkWatcher.Unwatch(cid)
-			}
+		// Only touch the checks that were added, changed, or removed
+		if err := c.syncChecks(ops, sreg, existingSvc.Checks, newSvc, newTask); err != nil {
+			return err
 		}
 	}
 
@@ -910,6 +1390,62 @@ func (c *ServiceClient) UpdateTask(old, newTask *TaskServices) error {
 	return nil
 }
 
+// syncChecks diffs the checks of a service that remains registered against
+// its existing checks. Added checks are registered and removed checks are
+// deregistered. A changed check has a new ID so it is replaced, restarting it
+// if it's a script check. Unchanged checks keep running uninterrupted. The IDs
+// of the service's checks are recorded in sreg.
+func (c *ServiceClient) syncChecks(ops *operations, sreg *ServiceRegistration, existing []*structs.ServiceCheck,
+	service *structs.Service, task *TaskServices) error {
+
+	existingChecks := make(map[string]*structs.ServiceCheck, len(existing))
+	for _, check := range existing {
+		existingChecks[makeCheckID(sreg.serviceID, check)] = check
+	}
+
+	var added []*structs.ServiceCheck
+	for _, check := range service.Checks {
+		checkID := makeCheckID(sreg.serviceID, check)
+		if _, ok := sreg.checkIDs[checkID]; ok {
+			continue
+		}
+		sreg.checkIDs[checkID] = struct{}{}
+
+		if _, exists := existingChecks[checkID]; exists {
+			// Check exists, so don't remove it
+			delete(existingChecks, checkID)
+		} else {
+			added = append(added, check)
+		}
+
+		// Update all watched checks as CheckRestart fields aren't part of ID
+		if check.TriggersRestarts() {
+			c.checkWatcher.Watch(task.AllocID, task.Name, checkID, check, task.Restarter)
+		}
+	}
+
+	// Remove existing checks not in updated service
+	for cid, check := range existingChecks {
+		ops.deregChecks = append(ops.deregChecks, cid)
+
+		// Unwatch checks
+		if check.TriggersRestarts() {
+			c.checkWatcher.Unwatch(cid)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	// Only build registrations for the added checks so unchanged script
+	// checks aren't replaced
+	addedSvc := *service
+	addedSvc.Checks = added
+	_, err := c.checkRegs(ops, sreg.serviceID, &addedSvc, task)
+	return err
+}
+
 // RemoveTask from Consul. Removes all service entries and checks.
 //
 // Actual communication with Consul is done asynchronously (see Run).
@@ -993,6 +1529,15 @@ func (c *ServiceClient) Shutdown() error {
 		close(c.shutdownCh)
 	}
 
+	// Persist the last results once script checks have exited
+	if c.checkCache != nil {
+		defer func() {
+			if err := c.checkCache.flush(); err != nil {
+				c.logger.Warn("failed to persist check results", "error", err)
+			}
+		}()
+	}
+
 	// Give run loop time to sync, but don't block indefinitely
 	deadline := time.After(c.shutdownWait)
 
@@ -1241,3 +1786,8 @@ func getAddress(addrMode, portLabel string, networks structs.Networks, driverNet
 		return "", 0, fmt.Errorf("invalid address mode %q", addrMode)
 	}
 }
+
+// jitter randomly shortens the backoff d by up to 1/retryJitterFraction.
+func jitter(r *lockedrand.Rand, d time.Duration) time.Duration {
+	return d - r.Stagger(d/retryJitterFraction)
+}
@@ -1,21 +1,128 @@
 package consul
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	metrics "github.com/armon/go-metrics"
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	log "github.com/hashicorp/go-hclog"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/helper/lockedrand"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
 )
 
+// defaultShutdownRetryBackoff is how long to wait between retries of a
+// failed final script check run on shutdown.
+const defaultShutdownRetryBackoff = 250 * time.Millisecond
+
+const (
+	// webhookTimeout bounds each check webhook delivery attempt
+	webhookTimeout = 5 * time.Second
+
+	// webhookAttempts is how many times delivering a check webhook is
+	// attempted before giving up
+	webhookAttempts = 3
+
+	// webhookQueueSize is how many status changes may wait to be delivered
+	// to a check's webhook before further changes are dropped
+	webhookQueueSize = 16
+
+	// defaultWebhookBackoff is how long to wait between webhook delivery
+	// attempts
+	defaultWebhookBackoff = time.Second
+)
+
+// Behaviors of script checks when the agent shuts down
+const (
+	// CheckShutdownDrain runs checks once more and reports their status
+	CheckShutdownDrain = "drain"
+
+	// CheckShutdownDeregister deregisters checks from Consul without
+	// running them again
+	CheckShutdownDeregister = "deregister"
+
+	// CheckShutdownLeave stops checks without running or deregistering them
+	CheckShutdownLeave = "leave"
+)
+
+// defaultErrorSummaryInterval is how often repeated identical errors updating
+// a check are summarized instead of logged individually.
+const defaultErrorSummaryInterval = 5 * time.Minute
+
 // heartbeater is the subset of consul agent functionality needed by script
 // checks to heartbeat
 type heartbeater interface {
 	UpdateTTL(id, output, status string) error
+	CheckDeregister(id string) error
+}
+
+// Tracer starts spans around script check executions and heartbeats. It is
+// optional; when nil no tracing occurs.
+type Tracer interface {
+	StartSpan(name string) Span
+}
+
+// Span is a single traced operation started by a Tracer.
+type Span interface {
+	// SetTag annotates the span with a key/value pair.
+	SetTag(k, v string)
+
+	// End marks the span as finished.
+	End()
+}
+
+// noopSpan is used in place of a Span when tracing is disabled.
+type noopSpan struct{}
+
+func (noopSpan) SetTag(string, string) {}
+func (noopSpan) End()                  {}
+
+// logTracer is a Tracer that logs each span's duration and tags at the trace
+// level when it ends.
+type logTracer struct {
+	logger log.Logger
+}
+
+// NewLogTracer returns a Tracer that logs finished spans to the given logger.
+func NewLogTracer(logger log.Logger) Tracer {
+	return &logTracer{logger: logger.Named("trace")}
+}
+
+func (t *logTracer) StartSpan(name string) Span {
+	return &logSpan{logger: t.logger, name: name, start: time.Now()}
+}
+
+// logSpan is a Span started by a logTracer.
+type logSpan struct {
+	logger log.Logger
+	name   string
+	start  time.Time
+	tags   []interface{}
+}
+
+func (s *logSpan) SetTag(k, v string) {
+	s.tags = append(s.tags, k, v)
+}
+
+func (s *logSpan) End() {
+	args := append([]interface{}{"span", s.name, "duration", time.Since(s.start)}, s.tags...)
+	s.logger.Trace("span finished", args...)
 }
 
 // contextExec allows canceling a ScriptExecutor with a context.
@@ -36,14 +143,40 @@ func newContextExec(ctx context.Context, exec interfaces.ScriptExecutor) *contex
 }
 
 type execResult struct {
-	buf  []byte
-	code int
-	err  error
+	buf    []byte
+	stderr []byte
+	code   int
+	err    error
 }
 
 // Exec a command until the timeout expires, the context is canceled, or the
 // underlying Exec returns.
 func (c *contextExec) Exec(timeout time.Duration, cmd string, args []string) ([]byte, int, error) {
+	res := c.run(timeout, func() execResult {
+		output, code, err := c.exec.Exec(timeout, cmd, args)
+		return execResult{buf: output, code: code, err: err}
+	})
+	return res.buf, res.code, res.err
+}
+
+// ExecWithOptions runs a command as configured by opts, returning stderr
+// separately. If the underlying executor can't apply options, the command is
+// run with Exec and stderr is always nil.
+func (c *contextExec) ExecWithOptions(timeout time.Duration, cmd string, args []string, opts interfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	optsExec, ok := c.exec.(interfaces.OptionsScriptExecutor)
+	if !ok {
+		output, code, err := c.Exec(timeout, cmd, args)
+		return output, nil, code, err
+	}
+	res := c.run(timeout+opts.KillGrace, func() execResult {
+		stdout, stderr, code, err := optsExec.ExecWithOptions(timeout, cmd, args, opts)
+		return execResult{buf: stdout, stderr: stderr, code: code, err: err}
+	})
+	return res.buf, res.stderr, res.code, res.err
+}
+
+// run f until the timeout expires, the context is canceled, or f returns.
+func (c *contextExec) run(timeout time.Duration, f func() execResult) execResult {
 	resCh := make(chan execResult, 1)
 
 	// Don't trust the underlying implementation to obey timeout
@@ -51,18 +184,18 @@ func (c *contextExec) Exec(timeout time.Duration, cmd string, args []string) ([]
 	defer cancel()
 
 	go func() {
-		output, code, err := c.exec.Exec(timeout, cmd, args)
+		res := f()
 		select {
-		case resCh <- execResult{output, code, err}:
+		case resCh <- res:
 		case <-ctx.Done():
 		}
 	}()
 
 	select {
 	case res := <-resCh:
-		return res.buf, res.code, res.err
+		return res
 	case <-ctx.Done():
-		return nil, 0, ctx.Err()
+		return execResult{err: ctx.Err()}
 	}
 }
 
@@ -72,6 +205,8 @@ type scriptHandle struct {
 	// cancel the script
 	cancel func()
 	exitCh chan struct{}
+
+	check *scriptCheck
 }
 
 // wait returns a chan that's closed when the script exits
@@ -79,6 +214,22 @@ func (s *scriptHandle) wait() <-chan struct{} {
 	return s.exitCh
 }
 
+// SetMaintenance puts the check into or takes it out of maintenance. While in
+// maintenance the script isn't run and the given status and reason are
+// reported to Consul instead, without deregistering the check. The status
+// defaults to passing and must otherwise be passing, warning, or critical.
+func (s *scriptHandle) SetMaintenance(enabled bool, status, reason string) error {
+	return s.check.setMaintenance(enabled, status, reason)
+}
+
+// checkMaintenance is the maintenance mode of a script check.
+type checkMaintenance struct {
+	enabled bool
+	status  string
+	reason  string
+	since   time.Time
+}
+
 // scriptCheck runs script checks via a ScriptExecutor and updates the
 // appropriate check's TTL when the script succeeds.
 type scriptCheck struct {
@@ -90,9 +241,85 @@ type scriptCheck struct {
 	exec  interfaces.ScriptExecutor
 	agent heartbeater
 
+	// tracer is used to trace check executions and heartbeats. May be nil.
+	tracer Tracer
+
 	// lastCheckOk is true if the last check was ok; otherwise false
 	lastCheckOk bool
 
+	// lastErr is the last error updating the check. Repeats of it are
+	// counted in errRepeats and summarized every errSummaryInterval, measured
+	// from errSummarized, instead of being logged individually.
+	lastErr            string
+	errRepeats         int
+	errSummarized      time.Time
+	errSummaryInterval time.Duration
+
+	// ran is 1 once the script has been executed; otherwise 0. Accessed
+	// with atomics.
+	ran int32
+
+	// lastState is the status reported by the last check run. It is used
+	// when forwarding partial output of a check that is still running.
+	lastState string
+
+	// lastStatus is the status last reported to Consul. A change triggers
+	// the check's webhook, if any.
+	lastStatus string
+
+	// webhook is the URL notified when the check's status changes. It
+	// defaults to the check's Webhook and is empty if webhooks aren't sent.
+	webhook string
+
+	// webhookBackoff is how long to wait between webhook delivery attempts
+	webhookBackoff time.Duration
+
+	// rand jitters the backoff between retries of the final run on shutdown
+	// and of webhook deliveries.
+	rand *lockedrand.Rand
+
+	// webhookLast is when the webhook was last notified. Status changes
+	// within the check's WebhookInterval of it are collapsed into
+	// webhookPending, which is sent when webhookTimer fires.
+	webhookLast    time.Time
+	webhookPending *webhookTransition
+	webhookTimer   *time.Timer
+
+	// webhookCh queues status changes for delivery to the webhook in order.
+	// It is closed once the run loop exits.
+	webhookCh     chan []*checkWebhookPayload
+	webhookClosed bool
+	webhookLock   sync.Mutex
+
+	// pool runs the check if the number of script checks running at once is
+	// limited. May be nil.
+	pool *checkPool
+
+	// startDelay delays the first run of the check to stagger the start of
+	// checks by their StartOrder.
+	startDelay time.Duration
+
+	// shutdownRetries is how many times the final run on shutdown is retried
+	// if it fails, waiting shutdownRetryBackoff between attempts.
+	shutdownRetries      int
+	shutdownRetryBackoff time.Duration
+
+	// shutdownBehavior is one of the CheckShutdown behaviors. If empty the
+	// check is drained.
+	shutdownBehavior string
+
+	// maint is the check's maintenance mode. While enabled the check isn't
+	// run and its fixed status is reported instead. maintCh is signaled when
+	// it changes.
+	maint     checkMaintenance
+	maintLock sync.Mutex
+	maintCh   chan struct{}
+
+	// sharedIDs are the IDs of identical deduplicated checks that are
+	// updated with the result of this check instead of running their own.
+	sharedIDs     map[string]struct{}
+	sharedIDsLock sync.Mutex
+
 	logger     log.Logger
 	shutdownCh <-chan struct{}
 }
@@ -100,10 +327,19 @@ type scriptCheck struct {
 // newScriptCheck creates a new scriptCheck. run() should be called once the
 // initial check is registered with Consul.
 func newScriptCheck(allocID, taskName, checkID string, check *structs.ServiceCheck,
-	exec interfaces.ScriptExecutor, agent heartbeater, logger log.Logger,
-	shutdownCh <-chan struct{}) *scriptCheck {
+	exec interfaces.ScriptExecutor, agent heartbeater, tracer Tracer,
+	logger log.Logger, shutdownCh <-chan struct{}) *scriptCheck {
 
 	logger = logger.ResetNamed("consul.checks").With("task", taskName, "alloc_id", allocID, "check", check.Name)
+	if _, ok := exec.(interfaces.OptionsScriptExecutor); !ok {
+		if check.KillGrace != 0 || check.StreamInterval != 0 || check.Nice != 0 || check.RlimitCPU != 0 || check.RlimitMemoryMB != 0 || check.SeparateStderr || check.TruncateFrom == structs.CheckTruncateHead {
+			logger.Warn("kill_grace, stream_interval, nice, rlimits, separate_stderr, and truncate_from are not supported by the task driver; ignoring")
+		}
+	}
+	lastState := check.InitialStatus
+	if lastState == "" {
+		lastState = api.HealthCritical
+	}
 	return &scriptCheck{
 		allocID:     allocID,
 		taskName:    taskName,
@@ -111,14 +347,26 @@ func newScriptCheck(allocID, taskName, checkID string, check *structs.ServiceChe
 		check:       check,
 		exec:        exec,
 		agent:       agent,
+		tracer:      tracer,
 		lastCheckOk: true, // start logging on first failure
+		lastState:   lastState,
+		lastStatus:  lastState,
 		logger:      logger,
 		shutdownCh:  shutdownCh,
+
+		webhook:              check.Webhook,
+		webhookBackoff:       defaultWebhookBackoff,
+		webhookCh:            make(chan []*checkWebhookPayload, webhookQueueSize),
+		errSummaryInterval:   defaultErrorSummaryInterval,
+		shutdownRetryBackoff: defaultShutdownRetryBackoff,
+		rand:                 lockedrand.New(nil),
+		maintCh:              make(chan struct{}, 1),
 	}
 }
 
 // run this script check and return its cancel func. If the shutdownCh is
-// closed the check will be run once more before exiting.
+// closed the check will be run once more before exiting unless its
+// shutdownBehavior says otherwise.
 func (s *scriptCheck) run() *scriptHandle {
 	ctx, cancel := context.WithCancel(context.Background())
 	exitCh := make(chan struct{})
@@ -127,10 +375,22 @@ func (s *scriptCheck) run() *scriptHandle {
 	// cancelation.
 	ctxExec := newContextExec(ctx, s.exec)
 
+	if s.webhook != "" {
+		go s.deliverWebhooks()
+	}
+
 	go func() {
 		defer close(exitCh)
-		timer := time.NewTimer(0)
+		defer s.stopWebhooks()
+		start := time.Now().Add(s.startDelay)
+		timer := time.NewTimer(s.startDelay + s.firstRunDelay(start))
 		defer timer.Stop()
+
+		// The first run reports any maintenance set before starting
+		select {
+		case <-s.maintCh:
+		default:
+		}
 		for {
 			// Block until check is removed, Nomad is shutting
 			// down, or the check interval is up
@@ -140,48 +400,75 @@ func (s *scriptCheck) run() *scriptHandle {
 				return
 			case <-s.shutdownCh:
 				// unblock but don't exit until after we heartbeat once more
+				// if draining
+				if !s.drainOnShutdown() {
+					s.exitOnShutdown()
+					return
+				}
+			case <-s.maintCh:
+				// maintenance toggled; report the new status immediately
 			case <-timer.C:
-				timer.Reset(s.check.Interval)
+				timer.Reset(s.nextRunDelay(time.Now()))
 			}
-			metrics.IncrCounter([]string{"client", "consul", "script_runs"}, 1)
 
-			// Execute check script with timeout
-			output, code, err := ctxExec.Exec(s.check.Timeout, s.check.Command, s.check.Args)
-			switch err {
-			case context.Canceled:
-				// check removed during execution; exit
-				return
-			case context.DeadlineExceeded:
-				metrics.IncrCounter([]string{"client", "consul", "script_timeouts"}, 1)
-				// If no error was returned, set one to make sure the task goes critical
-				if err == nil {
-					err = context.DeadlineExceeded
+			// Report the fixed status of checks in maintenance without
+			// running them
+			state, outputMsg, inMaint := s.maintenanceStatus()
+
+			// Retry failures of the final run on shutdown so a transient
+			// failure isn't reported as the check's last status
+			skipped := false
+			for attempt := 0; !inMaint; attempt++ {
+				var ok, queued bool
+				state, outputMsg, ok, queued = s.execPooled(ctx, ctxExec)
+				if !ok {
+					// check removed during execution or shutting down
+					// without draining; exit
+					if ctx.Err() == nil && s.shuttingDown() {
+						s.exitOnShutdown()
+					}
+					return
+				}
+				if !queued {
+					metrics.IncrCounter([]string{"client", "consul", "script_skipped"}, 1)
+					s.logger.Warn("skipping check run; all check workers are busy", "interval", s.check.Interval)
+					skipped = true
+					break
+				}
+				atomic.StoreInt32(&s.ran, 1)
+				if state == api.HealthPassing || attempt >= s.shutdownRetries || !s.shuttingDown() {
+					break
 				}
 
-				// Log deadline exceeded every time as it's a
-				// distinct issue from checks returning
-				// failures
-				s.logger.Warn("check timed out", "timeout", s.check.Timeout)
+				s.logger.Debug("retrying failed check before shutdown", "status", state, "attempt", attempt+1)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(jitter(s.rand, s.shutdownRetryBackoff)):
+				}
+			}
+			if skipped {
+				continue
 			}
 
-			state := api.HealthCritical
-			switch code {
-			case 0:
-				state = api.HealthPassing
-			case 1:
-				state = api.HealthWarning
+			if state != s.lastStatus {
+				s.notifyWebhook(s.lastStatus, state, outputMsg)
+				s.lastStatus = state
 			}
 
-			var outputMsg string
+			// Actually heartbeat the check and any checks sharing it
+			hbSpan := s.startSpan("script_check.heartbeat")
+			hbSpan.SetTag("status", state)
+			var err error
+			for _, id := range s.checkIDs() {
+				if updateErr := s.agent.UpdateTTL(id, outputMsg, s.statusName(state)); updateErr != nil {
+					err = updateErr
+				}
+			}
 			if err != nil {
-				state = api.HealthCritical
-				outputMsg = err.Error()
-			} else {
-				outputMsg = string(output)
+				hbSpan.SetTag("error", err.Error())
 			}
-
-			// Actually heartbeat the check
-			err = s.agent.UpdateTTL(s.id, outputMsg, state)
+			hbSpan.End()
 			select {
 			case <-ctx.Done():
 				// check has been removed; don't report errors
@@ -190,26 +477,717 @@ func (s *scriptCheck) run() *scriptHandle {
 			}
 
 			if err != nil {
-				if s.lastCheckOk {
-					s.lastCheckOk = false
-					s.logger.Warn("updating check failed", "error", err)
-				} else {
-					s.logger.Debug("updating check still failing", "error", err)
-				}
-
+				s.lastCheckOk = false
+				s.logUpdateError(err, time.Now())
 			} else if !s.lastCheckOk {
 				// Succeeded for the first time or after failing; log
 				s.lastCheckOk = true
-				s.logger.Info("updating check succeeded")
+				s.logger.Info("updating check succeeded", "suppressed_errors", s.errRepeats)
+				s.lastErr = ""
+				s.errRepeats = 0
 			}
 
 			select {
 			case <-s.shutdownCh:
 				// We've been told to exit and just heartbeated so exit
+				s.exitOnShutdown()
 				return
 			default:
 			}
 		}
 	}()
-	return &scriptHandle{cancel: cancel, exitCh: exitCh}
+	return &scriptHandle{cancel: cancel, exitCh: exitCh, check: s}
+}
+
+// logUpdateError logs an error updating the check. New errors are logged
+// immediately while repeats of the last error are only counted and
+// periodically summarized so an unavailable Consul doesn't flood the logs.
+func (s *scriptCheck) logUpdateError(err error, now time.Time) {
+	msg := err.Error()
+	if msg != s.lastErr {
+		s.lastErr = msg
+		s.errRepeats = 0
+		s.errSummarized = now
+		s.logger.Warn("updating check failed", "error", err)
+		return
+	}
+
+	s.errRepeats++
+	if since := now.Sub(s.errSummarized); since >= s.errSummaryInterval {
+		s.logger.Warn("updating check still failing", "error", err,
+			"occurrences", s.errRepeats, "interval", since)
+		s.errRepeats = 0
+		s.errSummarized = now
+	}
+}
+
+// execPooled runs the check script once, on the check worker pool if there is
+// one, and returns the results of execOnce. If the pool doesn't have room
+// within the check's interval, the run is skipped and false is returned for
+// queued. The final run on shutdown bypasses the pool.
+func (s *scriptCheck) execPooled(ctx context.Context, ctxExec *contextExec) (string, string, bool, bool) {
+	if s.pool == nil || s.shuttingDown() {
+		state, output, ok := s.execOnce(ctxExec)
+		return state, output, ok, true
+	}
+
+	type result struct {
+		state  string
+		output string
+		ok     bool
+	}
+	// claimed is set by whichever of the worker and this goroutine runs the
+	// check so it's never run by both. Accessed with atomics.
+	var claimed int32
+	resultCh := make(chan result, 1)
+	queued := s.pool.submit(ctx, s.check.Interval, func() {
+		if !atomic.CompareAndSwapInt32(&claimed, 0, 1) {
+			return
+		}
+		state, output, ok := s.execOnce(ctxExec)
+		resultCh <- result{state: state, output: output, ok: ok}
+	})
+	if !queued {
+		select {
+		case <-ctx.Done():
+			// check removed while waiting
+			return "", "", false, false
+		case <-s.shutdownCh:
+			// pool stopped; run the final check directly if draining
+			if !s.drainOnShutdown() {
+				return "", "", false, true
+			}
+			state, output, ok := s.execOnce(ctxExec)
+			return state, output, ok, true
+		default:
+			return "", "", true, false
+		}
+	}
+
+	select {
+	case r := <-resultCh:
+		return r.state, r.output, r.ok, true
+	case <-ctx.Done():
+		atomic.CompareAndSwapInt32(&claimed, 0, 1)
+		return "", "", false, true
+	case <-s.shutdownCh:
+	}
+
+	// The workers exit on shutdown so a queued run may never be picked up;
+	// run the final check directly if draining unless a worker already
+	// started it
+	if atomic.CompareAndSwapInt32(&claimed, 0, 1) {
+		if !s.drainOnShutdown() {
+			return "", "", false, true
+		}
+		state, output, ok := s.execOnce(ctxExec)
+		return state, output, ok, true
+	}
+	r := <-resultCh
+	return r.state, r.output, r.ok, true
+}
+
+// execOnce runs the check script once and returns the resulting status and
+// output to report. False is returned if the check was removed during
+// execution.
+func (s *scriptCheck) execOnce(ctxExec *contextExec) (string, string, bool) {
+	metrics.IncrCounter([]string{"client", "consul", "script_runs"}, 1)
+
+	// Execute check script with timeout
+	execSpan := s.startSpan("script_check.exec")
+	output, stderr, code, err := s.execScript(ctxExec)
+	switch err {
+	case context.Canceled:
+		// check removed during execution
+		execSpan.End()
+		return "", "", false
+	case context.DeadlineExceeded:
+		metrics.IncrCounter([]string{"client", "consul", "script_timeouts"}, 1)
+		// If no error was returned, set one to make sure the task goes critical
+		if err == nil {
+			err = context.DeadlineExceeded
+		}
+
+		// Log deadline exceeded every time as it's a
+		// distinct issue from checks returning
+		// failures
+		s.logger.Warn("check timed out", "timeout", s.check.Timeout)
+	}
+
+	state := api.HealthCritical
+	switch code {
+	case 0:
+		state = api.HealthPassing
+	case 1:
+		state = api.HealthWarning
+	}
+
+	msg, encode := output, s.check.Base64Output
+	if err != nil {
+		state = api.HealthCritical
+		msg, encode = []byte(err.Error()), false
+	} else if len(output) == 0 {
+		msg, encode = []byte(s.check.DefaultOutput), false
+	}
+
+	// Label stderr captured separately to help debug failing checks
+	if state == api.HealthPassing {
+		stderr = nil
+	}
+	outputMsg := s.outputMessage(msg, encode, stderr, drivers.CheckBufSize)
+	s.lastState = state
+	execSpan.SetTag("status", state)
+	execSpan.End()
+	return state, outputMsg, true
+}
+
+// limits returns the resource limits to run the check within.
+func (s *scriptCheck) limits() interfaces.ScriptLimits {
+	return interfaces.ScriptLimits{
+		Nice:     s.check.Nice,
+		CPU:      s.check.RlimitCPU,
+		MemoryMB: s.check.RlimitMemoryMB,
+	}
+}
+
+// firstRunDelay returns how long to wait before the check's first run. Checks
+// run immediately unless aligned to the clock, in which case they wait for
+// the next multiple of their interval.
+func (s *scriptCheck) firstRunDelay(now time.Time) time.Duration {
+	if !s.check.AlignToClock {
+		return 0
+	}
+	return untilBoundary(now, s.check.Interval)
+}
+
+// nextRunDelay returns how long to wait between runs of the check. Checks
+// aligned to the clock wait for the next multiple of their interval so
+// execution time doesn't cause them to drift.
+func (s *scriptCheck) nextRunDelay(now time.Time) time.Duration {
+	if !s.check.AlignToClock {
+		return s.check.Interval
+	}
+	if d := untilBoundary(now, s.check.Interval); d > 0 {
+		return d
+	}
+	return s.check.Interval
+}
+
+// untilBoundary returns the duration from now until the next wall-clock
+// multiple of interval, or 0 if now is on a boundary.
+func untilBoundary(now time.Time, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	if elapsed := now.Sub(now.Truncate(interval)); elapsed > 0 {
+		return interval - elapsed
+	}
+	return 0
+}
+
+// statusName returns the name reported to Consul for the given status,
+// honoring the check's StatusNames overrides.
+func (s *scriptCheck) statusName(status string) string {
+	if name, ok := s.check.StatusNames[status]; ok {
+		return name
+	}
+	return status
+}
+
+// checkWebhookPayload is the JSON body POSTed to a check's webhook when its
+// status changes.
+type checkWebhookPayload struct {
+	CheckID   string    `json:"check_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Output    string    `json:"output"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// webhookTransition is a status change waiting to be sent to a check's
+// webhook.
+type webhookTransition struct {
+	from   string
+	to     string
+	output string
+}
+
+// notifyWebhook asynchronously POSTs the status change to the check's
+// webhook for this check and any checks sharing it. Delivery failures are
+// logged and otherwise ignored.
+//
+// If the check has a WebhookInterval, status changes within the interval of
+// the last notification are collapsed into a single delayed notification
+// from the status last notified to the latest status.
+func (s *scriptCheck) notifyWebhook(from, to, output string) {
+	if s.webhook == "" {
+		return
+	}
+
+	s.webhookLock.Lock()
+	defer s.webhookLock.Unlock()
+
+	if s.check.WebhookInterval <= 0 {
+		s.sendWebhook(from, to, output)
+		return
+	}
+
+	if p := s.webhookPending; p != nil {
+		p.to, p.output = to, output
+		return
+	}
+
+	now := time.Now()
+	wait := s.check.WebhookInterval - now.Sub(s.webhookLast)
+	if s.webhookLast.IsZero() || wait <= 0 {
+		s.webhookLast = now
+		s.sendWebhook(from, to, output)
+		return
+	}
+
+	s.webhookPending = &webhookTransition{from: from, to: to, output: output}
+	s.webhookTimer = time.AfterFunc(wait, s.flushWebhook)
+}
+
+// flushWebhook sends the pending collapsed status change, if any. Nothing is
+// sent if the check flapped back to the status last notified.
+func (s *scriptCheck) flushWebhook() {
+	s.webhookLock.Lock()
+	defer s.webhookLock.Unlock()
+	s.flushWebhookLocked()
+}
+
+// flushWebhookLocked is flushWebhook for callers holding webhookLock.
+func (s *scriptCheck) flushWebhookLocked() {
+	p := s.webhookPending
+	s.webhookPending = nil
+	if p == nil || p.from == p.to {
+		return
+	}
+	s.webhookLast = time.Now()
+	s.sendWebhook(p.from, p.to, p.output)
+}
+
+// stopWebhooks is called when the run loop exits. It stops the timer of a
+// pending collapsed status change and sends the change immediately instead,
+// then closes the delivery queue once it's drained.
+func (s *scriptCheck) stopWebhooks() {
+	s.webhookLock.Lock()
+	defer s.webhookLock.Unlock()
+
+	if s.webhookClosed {
+		return
+	}
+	if s.webhookTimer != nil {
+		s.webhookTimer.Stop()
+	}
+	s.flushWebhookLocked()
+	s.webhookClosed = true
+	close(s.webhookCh)
+}
+
+// sendWebhook queues the status change for delivery to the check's webhook
+// for this check and any checks sharing it. The change is dropped if the
+// queue is full. It must be called with webhookLock held.
+func (s *scriptCheck) sendWebhook(from, to, output string) {
+	if s.webhookClosed {
+		return
+	}
+
+	now := time.Now().UTC()
+	var payloads []*checkWebhookPayload
+	for _, id := range s.checkIDs() {
+		payloads = append(payloads, &checkWebhookPayload{
+			CheckID:   id,
+			From:      from,
+			To:        to,
+			Output:    output,
+			Timestamp: now,
+		})
+	}
+
+	select {
+	case s.webhookCh <- payloads:
+	default:
+		s.logger.Warn("dropping check webhook; too many status changes waiting to be delivered",
+			"from", from, "to", to)
+	}
+}
+
+// deliverWebhooks POSTs queued status changes to the check's webhook one at a
+// time so they are received in the order they happened. It returns once the
+// queue is closed and drained.
+func (s *scriptCheck) deliverWebhooks() {
+	for payloads := range s.webhookCh {
+		for _, payload := range payloads {
+			s.postWebhook(payload)
+		}
+	}
+}
+
+// postWebhook delivers a single webhook payload, retrying failed attempts.
+func (s *scriptCheck) postWebhook(payload *checkWebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.Warn("failed to encode check webhook", "error", err)
+		return
+	}
+
+	client := cleanhttp.DefaultClient()
+	client.Timeout = webhookTimeout
+
+	for attempt := 1; ; attempt++ {
+		err = postWebhookOnce(client, s.webhook, body)
+		if err == nil {
+			return
+		}
+		if attempt >= webhookAttempts {
+			break
+		}
+
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-time.After(jitter(s.rand, s.webhookBackoff)):
+		}
+	}
+
+	s.logger.Warn("failed to deliver check webhook", "url", s.webhook,
+		"check_id", payload.CheckID, "attempts", webhookAttempts, "error", err)
+}
+
+// postWebhookOnce makes a single webhook delivery attempt. Non-2xx responses
+// are treated as failures.
+func postWebhookOnce(client *http.Client, url string, body []byte) error {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// setMaintenance updates the check's maintenance mode and wakes the run loop
+// so the change is reported immediately. An invalid status leaves the mode
+// unchanged.
+func (s *scriptCheck) setMaintenance(enabled bool, status, reason string) error {
+	switch status {
+	case "":
+		status = api.HealthPassing
+	case api.HealthPassing, api.HealthWarning, api.HealthCritical:
+	default:
+		return fmt.Errorf("invalid maintenance status %q: must be %q, %q, or %q",
+			status, api.HealthPassing, api.HealthWarning, api.HealthCritical)
+	}
+
+	s.maintLock.Lock()
+	since := s.maint.since
+	if !s.maint.enabled {
+		since = time.Now()
+	}
+	s.maint = checkMaintenance{enabled: enabled, status: status, reason: reason, since: since}
+	s.maintLock.Unlock()
+
+	if enabled {
+		s.logger.Info("check entering maintenance", "status", status, "reason", reason)
+	} else {
+		s.logger.Info("check leaving maintenance")
+	}
+
+	select {
+	case s.maintCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// maintenanceStatus returns the fixed status and reason to report if the check
+// is in maintenance.
+func (s *scriptCheck) maintenanceStatus() (string, string, bool) {
+	s.maintLock.Lock()
+	defer s.maintLock.Unlock()
+	return s.maint.status, s.maint.reason, s.maint.enabled
+}
+
+// maintenance returns the check's maintenance mode.
+func (s *scriptCheck) maintenance() checkMaintenance {
+	s.maintLock.Lock()
+	defer s.maintLock.Unlock()
+	return s.maint
+}
+
+// addSharedID updates the check with the given ID with the results of this
+// check.
+func (s *scriptCheck) addSharedID(id string) {
+	s.sharedIDsLock.Lock()
+	defer s.sharedIDsLock.Unlock()
+	if s.sharedIDs == nil {
+		s.sharedIDs = make(map[string]struct{})
+	}
+	s.sharedIDs[id] = struct{}{}
+}
+
+// removeSharedID stops updating the check with the given ID.
+func (s *scriptCheck) removeSharedID(id string) {
+	s.sharedIDsLock.Lock()
+	defer s.sharedIDsLock.Unlock()
+	delete(s.sharedIDs, id)
+}
+
+// sharedCheckIDs returns the sorted IDs of the checks sharing this check's
+// results.
+func (s *scriptCheck) sharedCheckIDs() []string {
+	s.sharedIDsLock.Lock()
+	defer s.sharedIDsLock.Unlock()
+	ids := make([]string, 0, len(s.sharedIDs))
+	for id := range s.sharedIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkIDs returns the IDs of all checks updated with this check's results.
+func (s *scriptCheck) checkIDs() []string {
+	return append([]string{s.id}, s.sharedCheckIDs()...)
+}
+
+// hasRun returns true if the script has been executed at least once.
+func (s *scriptCheck) hasRun() bool {
+	return atomic.LoadInt32(&s.ran) == 1
+}
+
+// drainOnShutdown returns true if the check is run once more when Nomad is
+// shutting down.
+func (s *scriptCheck) drainOnShutdown() bool {
+	return s.shutdownBehavior == "" || s.shutdownBehavior == CheckShutdownDrain
+}
+
+// exitOnShutdown deregisters the check and any checks sharing it if the
+// check's shutdownBehavior is to deregister.
+func (s *scriptCheck) exitOnShutdown() {
+	if s.shutdownBehavior != CheckShutdownDeregister {
+		return
+	}
+	for _, id := range s.checkIDs() {
+		if err := s.agent.CheckDeregister(id); err != nil {
+			s.logger.Warn("failed to deregister check on shutdown", "check_id", id, "error", err)
+		}
+	}
+}
+
+// shuttingDown returns true if Nomad is shutting down.
+func (s *scriptCheck) shuttingDown() bool {
+	select {
+	case <-s.shutdownCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// startSpan starts a span tagged with the check's ID. A no-op span is returned
+// if tracing is disabled.
+func (s *scriptCheck) startSpan(name string) Span {
+	if s.tracer == nil {
+		return noopSpan{}
+	}
+	span := s.tracer.StartSpan(name)
+	span.SetTag("check_id", s.id)
+	return span
+}
+
+// execScript runs the check script within the check's resource limits and
+// returns its output, stderr if the check captures it separately, exit code,
+// and error. A KillGrace gives a timed out script time to clean up before
+// it's killed. If the check has a StreamInterval, the latest line of output is
+// periodically forwarded to Consul while the script runs.
+func (s *scriptCheck) execScript(ctxExec *contextExec) ([]byte, []byte, int, error) {
+	opts := interfaces.ScriptExecOptions{
+		KillGrace:      s.check.KillGrace,
+		Limits:         s.limits(),
+		SeparateStderr: s.check.SeparateStderr,
+		KeepHead:       s.check.TruncateFrom == structs.CheckTruncateHead,
+	}
+	if s.check.StreamInterval <= 0 {
+		return ctxExec.ExecWithOptions(s.check.Timeout, s.check.Command, s.check.Args, opts)
+	}
+
+	var (
+		latest     []byte
+		latestLock sync.Mutex
+	)
+	doneCh := make(chan struct{})
+	exitCh := make(chan struct{})
+	go func() {
+		defer close(exitCh)
+		ticker := time.NewTicker(s.check.StreamInterval)
+		defer ticker.Stop()
+
+		var sent []byte
+		for {
+			select {
+			case <-doneCh:
+				return
+			case <-ticker.C:
+			}
+
+			latestLock.Lock()
+			line := latest
+			latestLock.Unlock()
+			if len(line) == 0 || bytes.Equal(line, sent) {
+				continue
+			}
+			sent = line
+
+			for _, id := range s.checkIDs() {
+				output := s.outputMessage(line, s.check.Base64Output, nil, drivers.CheckBufSize)
+				if err := s.agent.UpdateTTL(id, output, s.statusName(s.lastState)); err != nil {
+					s.logger.Debug("updating check with partial output failed", "check_id", id, "error", err)
+				}
+			}
+		}
+	}()
+
+	opts.Output = func(partial []byte) {
+		line := lastLine(partial)
+		if len(line) == 0 {
+			return
+		}
+		latestLock.Lock()
+		latest = line
+		latestLock.Unlock()
+	}
+	output, stderr, code, err := ctxExec.ExecWithOptions(s.check.Timeout, s.check.Command, s.check.Args, opts)
+
+	// Wait for any in-flight partial update so it can't overwrite the final
+	// result
+	close(doneCh)
+	<-exitCh
+	return output, stderr, code, err
+}
+
+// stderrLabel prefixes stderr in the output reported to Consul.
+const stderrLabel = "stderr: "
+
+// outputMessage returns the output reported to Consul: msg followed by
+// stderr, if any, on its own labeled line. If encode is set the whole message,
+// including the labeled stderr, is base64 encoded so it decodes as a single
+// payload. Otherwise stderr is base64 encoded on its own if the check's
+// output may be binary. The raw output is truncated before it's encoded so
+// the message fits in max bytes, keeping the end of the message selected by
+// the check's TruncateFrom.
+func (s *scriptCheck) outputMessage(msg []byte, encode bool, stderr []byte, max int) string {
+	from := s.check.TruncateFrom
+	if len(stderr) == 0 {
+		return formatOutput(msg, encode, from, max)
+	}
+	if encode {
+		return formatOutput(appendStderr(msg, stderr), true, from, max)
+	}
+
+	// Keep as much of the end being kept as fits, then fill the remaining
+	// room with the other end
+	if from == structs.CheckTruncateHead {
+		out := formatOutput(msg, false, from, max)
+		sep := ""
+		if out != "" && !strings.HasSuffix(out, "\n") {
+			sep = "\n"
+		}
+		room := max - len(out) - len(sep) - len(stderrLabel)
+		if room <= 0 {
+			return out
+		}
+		return out + sep + stderrLabel + formatOutput(stderr, s.check.Base64Output, from, room)
+	}
+
+	errOut := stderrLabel + formatOutput(stderr, s.check.Base64Output, from, max-len(stderrLabel))
+	room := max - len(errOut)
+	if len(msg) > 0 && msg[len(msg)-1] != '\n' {
+		// The end of msg is kept, so it needs a newline before stderr
+		room--
+		if out := formatOutput(msg, false, from, room); out != "" {
+			return out + "\n" + errOut
+		}
+		return errOut
+	}
+	return formatOutput(msg, false, from, room) + errOut
+}
+
+// appendStderr returns output followed by stderr on its own labeled line.
+func appendStderr(output, stderr []byte) []byte {
+	buf := make([]byte, 0, len(output)+1+len(stderrLabel)+len(stderr))
+	buf = append(buf, output...)
+	if len(buf) > 0 && buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, stderrLabel...)
+	return append(buf, stderr...)
+}
+
+// formatOutput converts raw script output to a string of at most max bytes,
+// base64 encoding it if encode is set. The output is truncated before being
+// encoded so the result is always valid base64, and otherwise without
+// splitting UTF-8 characters.
+func formatOutput(buf []byte, encode bool, from string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if encode {
+		return base64.StdEncoding.EncodeToString(truncateOutput(buf, from, base64.StdEncoding.DecodedLen(max)))
+	}
+	return string(truncateText(buf, from, max))
+}
+
+// truncateOutput limits output to max bytes. The beginning of the output is
+// kept if from is structs.CheckTruncateHead; otherwise the end is kept.
+func truncateOutput(output []byte, from string, max int) []byte {
+	if len(output) <= max {
+		return output
+	}
+	if from == structs.CheckTruncateHead {
+		return output[:max]
+	}
+	return output[len(output)-max:]
+}
+
+// truncateText is like truncateOutput but also drops a UTF-8 character split
+// by the truncation.
+func truncateText(output []byte, from string, max int) []byte {
+	if len(output) <= max {
+		return output
+	}
+	if from == structs.CheckTruncateHead {
+		end := max
+		for i := 0; i < utf8.UTFMax-1 && end > 0 && !utf8.RuneStart(output[end]); i++ {
+			end--
+		}
+		if !utf8.RuneStart(output[end]) {
+			// Not UTF-8, so there's no character to keep whole
+			end = max
+		}
+		return output[:end]
+	}
+	start := len(output) - max
+	for i := 0; i < utf8.UTFMax-1 && start < len(output) && !utf8.RuneStart(output[start]); i++ {
+		start++
+	}
+	if start < len(output) && !utf8.RuneStart(output[start]) {
+		start = len(output) - max
+	}
+	return output[start:]
+}
+
+// lastLine returns a copy of the last non-empty line in buf.
+func lastLine(buf []byte) []byte {
+	buf = bytes.TrimRight(buf, "\r\n")
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		buf = buf[i+1:]
+	}
+	return append([]byte(nil), buf...)
 }
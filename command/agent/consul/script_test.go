@@ -1,18 +1,33 @@
 package consul
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hashicorp/consul/api"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocrunner/taskrunner/interfaces"
+	"github.com/hashicorp/nomad/helper"
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/helper/testtask"
 	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/plugins/drivers"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -78,7 +93,7 @@ func TestConsulScript_Exec_Cancel(t *testing.T) {
 	defer cancel()
 
 	// pass nil for heartbeater as it shouldn't be called
-	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, nil, testlog.HCLogger(t), nil)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, nil, nil, testlog.HCLogger(t), nil)
 	handle := check.run()
 
 	// wait until Exec is called
@@ -109,7 +124,8 @@ type execStatus struct {
 // fakeHeartbeater implements the heartbeater interface to allow mocking out
 // Consul in script executor tests.
 type fakeHeartbeater struct {
-	updates chan execStatus
+	updates     chan execStatus
+	deregisters chan string
 }
 
 func (f *fakeHeartbeater) UpdateTTL(checkID, output, status string) error {
@@ -117,8 +133,16 @@ func (f *fakeHeartbeater) UpdateTTL(checkID, output, status string) error {
 	return nil
 }
 
+func (f *fakeHeartbeater) CheckDeregister(checkID string) error {
+	f.deregisters <- checkID
+	return nil
+}
+
 func newFakeHeartbeater() *fakeHeartbeater {
-	return &fakeHeartbeater{updates: make(chan execStatus)}
+	return &fakeHeartbeater{
+		updates:     make(chan execStatus),
+		deregisters: make(chan string, 10),
+	}
 }
 
 // TestConsulScript_Exec_TimeoutBasic asserts a script will be killed when the
@@ -136,7 +160,7 @@ func TestConsulScript_Exec_TimeoutBasic(t *testing.T) {
 	defer cancel()
 
 	hb := newFakeHeartbeater()
-	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, testlog.HCLogger(t), nil)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
 	handle := check.run()
 	defer handle.cancel() // just-in-case cleanup
 	<-exec.running
@@ -189,7 +213,7 @@ func TestConsulScript_Exec_TimeoutCritical(t *testing.T) {
 		Timeout:  time.Nanosecond,
 	}
 	hb := newFakeHeartbeater()
-	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, sleeperExec{}, hb, testlog.HCLogger(t), nil)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, sleeperExec{}, hb, nil, testlog.HCLogger(t), nil)
 	handle := check.run()
 	defer handle.cancel() // just-in-case cleanup
 
@@ -211,9 +235,15 @@ func TestConsulScript_Exec_TimeoutCritical(t *testing.T) {
 type simpleExec struct {
 	code int
 	err  error
+
+	// silent causes Exec to return no output
+	silent bool
 }
 
 func (s simpleExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	if s.silent {
+		return []byte{}, s.code, s.err
+	}
 	return []byte(fmt.Sprintf("code=%d err=%v", s.code, s.err)), s.code, s.err
 }
 
@@ -234,7 +264,7 @@ func TestConsulScript_Exec_Shutdown(t *testing.T) {
 	hb := newFakeHeartbeater()
 	shutdown := make(chan struct{})
 	exec := newSimpleExec(0, nil)
-	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, testlog.HCLogger(t), shutdown)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), shutdown)
 	handle := check.run()
 	defer handle.cancel() // just-in-case cleanup
 
@@ -258,6 +288,199 @@ func TestConsulScript_Exec_Shutdown(t *testing.T) {
 	}
 }
 
+// flakyExec fails the first failures executions and succeeds afterwards.
+type flakyExec struct {
+	failures int
+
+	calls int
+	mu    sync.Mutex
+}
+
+func (f *flakyExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return []byte("transient failure"), 2, nil
+	}
+	return []byte("ok"), 0, nil
+}
+
+func (f *flakyExec) numCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// TestConsulScript_Exec_ShutdownBehavior asserts a script check is run once
+// more, deregistered, or left alone on shutdown depending on its
+// shutdownBehavior.
+func TestConsulScript_Exec_ShutdownBehavior(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		behavior   string
+		execs      int
+		update     bool
+		deregister bool
+	}{
+		{behavior: "", execs: 1, update: true},
+		{behavior: CheckShutdownDrain, execs: 1, update: true},
+		{behavior: CheckShutdownDeregister, deregister: true},
+		{behavior: CheckShutdownLeave},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.behavior, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			serviceCheck := structs.ServiceCheck{
+				Name:     "sleeper",
+				Interval: time.Hour,
+				Timeout:  3 * time.Second,
+			}
+
+			hb := newFakeHeartbeater()
+			shutdown := make(chan struct{})
+			exec := &flakyExec{}
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), shutdown)
+			check.shutdownBehavior = tc.behavior
+
+			// Delay the first run so only the shutdown triggers a run
+			check.startDelay = time.Hour
+			handle := check.run()
+			defer handle.cancel() // just-in-case cleanup
+			close(shutdown)
+
+			if tc.update {
+				select {
+				case update := <-hb.updates:
+					require.Equal("checkid", update.checkID)
+					require.Equal(api.HealthPassing, update.status)
+				case <-time.After(3 * time.Second):
+					t.Fatalf("timed out waiting for final check update")
+				}
+			}
+
+			select {
+			case <-handle.wait():
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exit")
+			}
+
+			require.Equal(tc.execs, exec.numCalls())
+			if tc.deregister {
+				require.Len(hb.deregisters, 1)
+				require.Equal("checkid", <-hb.deregisters)
+			} else {
+				require.Empty(hb.deregisters)
+			}
+		})
+	}
+}
+
+// TestConsulScript_Exec_ShutdownRetry asserts a failed final run on shutdown
+// is retried before its status is reported.
+func TestConsulScript_Exec_ShutdownRetry(t *testing.T) {
+	t.Parallel()
+	serviceCheck := structs.ServiceCheck{
+		Name:     "flaky",
+		Interval: time.Hour,
+		Timeout:  3 * time.Second,
+	}
+
+	hb := newFakeHeartbeater()
+	shutdown := make(chan struct{})
+	exec := &flakyExec{failures: 1}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), shutdown)
+	check.shutdownRetries = 2
+	check.shutdownRetryBackoff = 10 * time.Millisecond
+
+	// Shutdown before running so the first run is the final run
+	close(shutdown)
+	handle := check.run()
+	defer handle.cancel() // just-in-case cleanup
+
+	select {
+	case update := <-hb.updates:
+		require.Equal(t, api.HealthPassing, update.status)
+		require.Equal(t, "ok", update.output)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to heartbeat")
+	}
+
+	select {
+	case <-handle.wait():
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exit")
+	}
+	require.Equal(t, 2, exec.numCalls())
+
+	// Only the retried result is reported
+	select {
+	case update := <-hb.updates:
+		t.Fatalf("unexpected update: %#v", update)
+	default:
+	}
+}
+
+// TestConsulScript_Maintenance asserts checks in maintenance report their
+// fixed status without being executed.
+func TestConsulScript_Maintenance(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	serviceCheck := structs.ServiceCheck{
+		Name:     "maint",
+		Interval: time.Hour,
+		Timeout:  3 * time.Second,
+	}
+
+	hb := newFakeHeartbeater()
+	exec := &flakyExec{}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+	require.NoError(check.setMaintenance(true, api.HealthWarning, "upgrading"))
+	handle := check.run()
+	defer handle.cancel()
+
+	next := func() execStatus {
+		select {
+		case update := <-hb.updates:
+			return update
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for script check to heartbeat")
+		}
+		return execStatus{}
+	}
+
+	update := next()
+	require.Equal(api.HealthWarning, update.status)
+	require.Equal("upgrading", update.output)
+	require.Zero(exec.numCalls())
+
+	// Leaving maintenance runs the check immediately
+	require.NoError(handle.SetMaintenance(false, "", ""))
+	update = next()
+	require.Equal(api.HealthPassing, update.status)
+	require.Equal("ok", update.output)
+	require.Equal(1, exec.numCalls())
+
+	// Entering maintenance again defaults to passing
+	require.NoError(handle.SetMaintenance(true, "", "paused"))
+	update = next()
+	require.Equal(api.HealthPassing, update.status)
+	require.Equal("paused", update.output)
+	require.Equal(1, exec.numCalls())
+
+	// An invalid status is rejected and the maintenance mode is unchanged
+	require.Error(handle.SetMaintenance(true, "maintenance", "bogus"))
+	maint := check.maintenance()
+	require.True(maint.enabled)
+	require.Equal(api.HealthPassing, maint.status)
+	require.Equal("paused", maint.reason)
+}
+
 func TestConsulScript_Exec_Codes(t *testing.T) {
 	run := func(code int, err error, expected string) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -271,7 +494,7 @@ func TestConsulScript_Exec_Codes(t *testing.T) {
 			hb := newFakeHeartbeater()
 			shutdown := make(chan struct{})
 			exec := newSimpleExec(code, err)
-			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, testlog.HCLogger(t), shutdown)
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), shutdown)
 			handle := check.run()
 			defer handle.cancel()
 
@@ -307,3 +530,894 @@ func TestConsulScript_Exec_Codes(t *testing.T) {
 	t.Run("Error-2", run(2, err, api.HealthCritical))
 	t.Run("Error-9000", run(9000, err, api.HealthCritical))
 }
+
+// TestConsulScript_Exec_DefaultOutput asserts the check's DefaultOutput is
+// reported when a script prints nothing.
+func TestConsulScript_Exec_DefaultOutput(t *testing.T) {
+	run := func(code int, defaultOutput, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			serviceCheck := structs.ServiceCheck{
+				Name:          "test",
+				Interval:      time.Hour,
+				Timeout:       3 * time.Second,
+				DefaultOutput: defaultOutput,
+			}
+
+			hb := newFakeHeartbeater()
+			exec := simpleExec{code: code, silent: true}
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+			handle := check.run()
+			defer handle.cancel()
+
+			select {
+			case update := <-hb.updates:
+				require.Equal(t, expected, update.output)
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exec")
+			}
+		}
+	}
+
+	t.Run("Passing", run(0, "check passed", "check passed"))
+	t.Run("Warning", run(1, "check passed", "check passed"))
+	t.Run("Unset", run(0, "", ""))
+}
+
+// fakeSpan records the tags set on it and whether it was ended.
+type fakeSpan struct {
+	tracer *fakeTracer
+	name   string
+	tags   map[string]string
+	ended  bool
+}
+
+func (f *fakeSpan) SetTag(k, v string) {
+	f.tracer.mu.Lock()
+	defer f.tracer.mu.Unlock()
+	f.tags[k] = v
+}
+
+func (f *fakeSpan) End() {
+	f.tracer.mu.Lock()
+	defer f.tracer.mu.Unlock()
+	f.ended = true
+}
+
+// fakeTracer implements Tracer and records every span it starts.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (f *fakeTracer) StartSpan(name string) Span {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	span := &fakeSpan{tracer: f, name: name, tags: make(map[string]string)}
+	f.spans = append(f.spans, span)
+	return span
+}
+
+func (f *fakeTracer) Spans() []fakeSpan {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	spans := make([]fakeSpan, len(f.spans))
+	for i, s := range f.spans {
+		spans[i] = *s
+		spans[i].tags = helper.CopyMapStringString(s.tags)
+	}
+	return spans
+}
+
+// TestConsulScript_Exec_Tracing asserts spans are started and ended around
+// each execution and heartbeat when a Tracer is set.
+func TestConsulScript_Exec_Tracing(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	serviceCheck := structs.ServiceCheck{
+		Name:     "test",
+		Interval: time.Hour,
+		Timeout:  3 * time.Second,
+	}
+
+	hb := newFakeHeartbeater()
+	tracer := &fakeTracer{}
+	exec := newSimpleExec(1, nil)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, tracer, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case <-hb.updates:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+
+	var spans []fakeSpan
+	testutil.WaitForResult(func() (bool, error) {
+		spans = tracer.Spans()
+		if len(spans) != 2 {
+			return false, fmt.Errorf("expected 2 spans but found %d", len(spans))
+		}
+		for _, span := range spans {
+			if !span.ended {
+				return false, fmt.Errorf("span %q not ended", span.name)
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	require.Equal("script_check.exec", spans[0].name)
+	require.Equal("script_check.heartbeat", spans[1].name)
+	for _, span := range spans {
+		require.Equal("checkid", span.tags["check_id"])
+		require.Equal(api.HealthWarning, span.tags["status"])
+	}
+}
+
+// streamingExec implements OptionsScriptExecutor by emitting each line to
+// the Output option after a delay.
+type streamingExec struct {
+	lines []string
+	delay time.Duration
+}
+
+func (s streamingExec) Exec(timeout time.Duration, cmd string, args []string) ([]byte, int, error) {
+	buf, _, code, err := s.ExecWithOptions(timeout, cmd, args, interfaces.ScriptExecOptions{})
+	return buf, code, err
+}
+
+func (s streamingExec) ExecWithOptions(_ time.Duration, _ string, _ []string, opts interfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	var buf []byte
+	for _, line := range s.lines {
+		time.Sleep(s.delay)
+		if opts.Output != nil {
+			opts.Output([]byte(line + "\n"))
+		}
+		buf = append(buf, line+"\n"...)
+	}
+	time.Sleep(s.delay)
+	return buf, nil, 0, nil
+}
+
+// TestConsulScript_Exec_Streaming asserts partial output of a running check is
+// forwarded to Consul before the final result.
+func TestConsulScript_Exec_Streaming(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	serviceCheck := structs.ServiceCheck{
+		Name:           "test",
+		Interval:       time.Hour,
+		Timeout:        10 * time.Second,
+		StreamInterval: 50 * time.Millisecond,
+	}
+
+	hb := newFakeHeartbeater()
+	exec := streamingExec{
+		lines: []string{"step 1", "step 2", "step 3"},
+		delay: 250 * time.Millisecond,
+	}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	var updates []execStatus
+	for {
+		select {
+		case update := <-hb.updates:
+			updates = append(updates, update)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for script check to exec")
+		}
+		if updates[len(updates)-1].status == api.HealthPassing {
+			break
+		}
+	}
+
+	// Each line was forwarded with the initial status while running, followed
+	// by the final output
+	require.Len(updates, 4)
+	for i, update := range updates[:3] {
+		require.Equal("checkid", update.checkID)
+		require.Equal(fmt.Sprintf("step %d", i+1), update.output)
+		require.Equal(api.HealthCritical, update.status)
+	}
+	require.Equal("step 1\nstep 2\nstep 3\n", updates[3].output)
+}
+
+func TestConsulScript_LastLine(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.Equal("b", string(lastLine([]byte("a\nb\n"))))
+	require.Equal("b", string(lastLine([]byte("a\r\nb\r\n"))))
+	require.Equal("partial", string(lastLine([]byte("a\npartial"))))
+	require.Empty(lastLine([]byte("\n")))
+}
+
+// stderrExec implements OptionsScriptExecutor by returning fixed stdout and
+// stderr, combining them unless SeparateStderr is set.
+type stderrExec struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+func (s stderrExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	return []byte(s.stdout + s.stderr), s.code, nil
+}
+
+func (s stderrExec) ExecWithOptions(_ time.Duration, _ string, _ []string, opts interfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	if !opts.SeparateStderr {
+		return []byte(s.stdout + s.stderr), nil, s.code, nil
+	}
+	return []byte(s.stdout), []byte(s.stderr), s.code, nil
+}
+
+// TestConsulScript_Exec_Stderr asserts stderr is labeled in the output of
+// failing checks capturing it separately and otherwise left combined with
+// stdout.
+func TestConsulScript_Exec_Stderr(t *testing.T) {
+	run := func(separate bool, code int, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			serviceCheck := structs.ServiceCheck{
+				Name:           "test",
+				Interval:       time.Hour,
+				Timeout:        3 * time.Second,
+				SeparateStderr: separate,
+			}
+
+			hb := newFakeHeartbeater()
+			exec := stderrExec{stdout: "some output\n", stderr: "boom\n", code: code}
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+			handle := check.run()
+			defer handle.cancel()
+
+			select {
+			case update := <-hb.updates:
+				require.Equal(t, expected, update.output)
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exec")
+			}
+		}
+	}
+
+	t.Run("Passing", run(true, 0, "some output\n"))
+	t.Run("Warning", run(true, 1, "some output\nstderr: boom\n"))
+	t.Run("Critical", run(true, 2, "some output\nstderr: boom\n"))
+	t.Run("Combined", run(false, 2, "some output\nboom\n"))
+}
+
+// TestConsulScript_Exec_StderrBase64 asserts the labeled stderr of a failing
+// check is encoded along with its output so the reported output decodes as a
+// single base64 payload.
+func TestConsulScript_Exec_StderrBase64(t *testing.T) {
+	t.Parallel()
+	serviceCheck := structs.ServiceCheck{
+		Name:           "test",
+		Interval:       time.Hour,
+		Timeout:        3 * time.Second,
+		SeparateStderr: true,
+		Base64Output:   true,
+	}
+
+	hb := newFakeHeartbeater()
+	exec := stderrExec{stdout: "some\xffoutput", stderr: "boom\xfe\n", code: 2}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case update := <-hb.updates:
+		decoded, err := base64.StdEncoding.DecodeString(update.output)
+		require.NoError(t, err)
+		require.Equal(t, "some\xffoutput\nstderr: boom\xfe\n", string(decoded))
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+}
+
+// TestConsulScript_Exec_TruncateFrom asserts oversized output is truncated
+// keeping the configured end.
+func TestConsulScript_Exec_TruncateFrom(t *testing.T) {
+	output := "begin" + strings.Repeat("x", 2*drivers.CheckBufSize) + "end"
+
+	run := func(truncateFrom string, keepsBegin bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			serviceCheck := structs.ServiceCheck{
+				Name:         "test",
+				Interval:     time.Hour,
+				Timeout:      3 * time.Second,
+				TruncateFrom: truncateFrom,
+			}
+
+			hb := newFakeHeartbeater()
+			exec := stderrExec{stdout: output}
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+			handle := check.run()
+			defer handle.cancel()
+
+			select {
+			case update := <-hb.updates:
+				require.Len(t, update.output, drivers.CheckBufSize)
+				require.Equal(t, keepsBegin, strings.HasPrefix(update.output, "begin"))
+				require.Equal(t, !keepsBegin, strings.HasSuffix(update.output, "end"))
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exec")
+			}
+		}
+	}
+
+	t.Run("Head", run(structs.CheckTruncateHead, true))
+	t.Run("Tail", run(structs.CheckTruncateTail, false))
+	t.Run("Default", run("", false))
+}
+
+// TestConsulScript_Exec_TruncateBase64 asserts oversized binary output is
+// truncated before being base64 encoded so it still decodes.
+func TestConsulScript_Exec_TruncateBase64(t *testing.T) {
+	raw := make([]byte, 2*drivers.CheckBufSize)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+
+	run := func(truncateFrom string, keepsBegin bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			serviceCheck := structs.ServiceCheck{
+				Name:         "test",
+				Interval:     time.Hour,
+				Timeout:      3 * time.Second,
+				TruncateFrom: truncateFrom,
+				Base64Output: true,
+			}
+
+			hb := newFakeHeartbeater()
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, binaryExec{output: raw}, hb, nil, testlog.HCLogger(t), nil)
+			handle := check.run()
+			defer handle.cancel()
+
+			select {
+			case update := <-hb.updates:
+				require.True(t, len(update.output) <= drivers.CheckBufSize)
+				decoded, err := base64.StdEncoding.DecodeString(update.output)
+				require.NoError(t, err)
+				require.NotEmpty(t, decoded)
+				if keepsBegin {
+					require.Equal(t, raw[:len(decoded)], decoded)
+				} else {
+					require.Equal(t, raw[len(raw)-len(decoded):], decoded)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exec")
+			}
+		}
+	}
+
+	t.Run("Head", run(structs.CheckTruncateHead, true))
+	t.Run("Tail", run(structs.CheckTruncateTail, false))
+}
+
+// TestConsulScript_Exec_TruncateUTF8 asserts truncating output doesn't split
+// a multibyte character.
+func TestConsulScript_Exec_TruncateUTF8(t *testing.T) {
+	// Place a 3 byte character across the truncation point at either end
+	pad := strings.Repeat("x", drivers.CheckBufSize-1)
+	output := pad + "€" + strings.Repeat("y", drivers.CheckBufSize) + "€" + pad
+
+	run := func(truncateFrom string) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			serviceCheck := structs.ServiceCheck{
+				Name:         "test",
+				Interval:     time.Hour,
+				Timeout:      3 * time.Second,
+				TruncateFrom: truncateFrom,
+			}
+
+			hb := newFakeHeartbeater()
+			exec := stderrExec{stdout: output}
+			check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+			handle := check.run()
+			defer handle.cancel()
+
+			select {
+			case update := <-hb.updates:
+				require.Equal(t, pad, update.output)
+				require.True(t, utf8.ValidString(update.output))
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for script check to exec")
+			}
+		}
+	}
+
+	t.Run("Head", run(structs.CheckTruncateHead))
+	t.Run("Tail", run(structs.CheckTruncateTail))
+}
+
+// TestConsulScript_Webhook asserts a script check's webhook is notified when
+// its status changes and that failed deliveries are retried.
+func TestConsulScript_Webhook(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	payloads := make(chan checkWebhookPayload, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to exercise retries
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var payload checkWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("error decoding webhook payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer ts.Close()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:          "test",
+		Interval:      time.Hour,
+		Timeout:       3 * time.Second,
+		InitialStatus: api.HealthPassing,
+		Webhook:       ts.URL,
+	}
+
+	hb := newFakeHeartbeater()
+	exec := newSimpleExec(2, nil)
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+	check.webhookBackoff = 10 * time.Millisecond
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case payload := <-payloads:
+		require.Equal(t, "checkid", payload.CheckID)
+		require.Equal(t, api.HealthPassing, payload.From)
+		require.Equal(t, api.HealthCritical, payload.To)
+		require.Equal(t, "code=2 err=<nil>", payload.Output)
+		require.False(t, payload.Timestamp.IsZero())
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for webhook")
+	}
+	require.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+}
+
+// TestConsulScript_WebhookInterval asserts rapid status changes of a script
+// check are collapsed into a single delayed webhook notification of the
+// final status.
+func TestConsulScript_WebhookInterval(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	payloads := make(chan checkWebhookPayload, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload checkWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("error decoding webhook payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer ts.Close()
+
+	interval := 300 * time.Millisecond
+	serviceCheck := structs.ServiceCheck{
+		Name:            "test",
+		Interval:        time.Hour,
+		Timeout:         3 * time.Second,
+		Webhook:         ts.URL,
+		WebhookInterval: interval,
+	}
+
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, newSimpleExec(0, nil), hb, nil, testlog.HCLogger(t), nil)
+	go check.deliverWebhooks()
+
+	next := func() checkWebhookPayload {
+		select {
+		case payload := <-payloads:
+			return payload
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for webhook")
+		}
+		return checkWebhookPayload{}
+	}
+
+	// The first change is sent immediately
+	start := time.Now()
+	check.notifyWebhook(api.HealthPassing, api.HealthCritical, "down")
+	payload := next()
+	require.Equal(api.HealthPassing, payload.From)
+	require.Equal(api.HealthCritical, payload.To)
+
+	// Flaps within the interval are collapsed into the final status
+	check.notifyWebhook(api.HealthCritical, api.HealthPassing, "up")
+	check.notifyWebhook(api.HealthPassing, api.HealthCritical, "down")
+	check.notifyWebhook(api.HealthCritical, api.HealthWarning, "degraded")
+	payload = next()
+	require.True(time.Since(start) >= interval, "sent after %v", time.Since(start))
+	require.Equal(api.HealthCritical, payload.From)
+	require.Equal(api.HealthWarning, payload.To)
+	require.Equal("degraded", payload.Output)
+
+	// Flapping back to the status last notified sends nothing
+	check.notifyWebhook(api.HealthWarning, api.HealthPassing, "up")
+	check.notifyWebhook(api.HealthPassing, api.HealthWarning, "degraded")
+	select {
+	case payload := <-payloads:
+		t.Fatalf("unexpected webhook %#v", payload)
+	case <-time.After(2 * interval):
+	}
+
+	// A change collapsed when the run loop exits is sent without waiting for
+	// the interval
+	check.notifyWebhook(api.HealthWarning, api.HealthCritical, "down")
+	payload = next()
+	require.Equal(api.HealthCritical, payload.To)
+	start = time.Now()
+	check.notifyWebhook(api.HealthCritical, api.HealthPassing, "up")
+	check.stopWebhooks()
+	payload = next()
+	require.True(time.Since(start) < interval, "sent after %v", time.Since(start))
+	require.Equal(api.HealthPassing, payload.To)
+
+	// Nothing is sent once stopped
+	check.notifyWebhook(api.HealthPassing, api.HealthCritical, "down")
+	select {
+	case payload := <-payloads:
+		t.Fatalf("unexpected webhook %#v", payload)
+	case <-time.After(2 * interval):
+	}
+}
+
+// TestConsulScript_WebhookOrder asserts status changes are delivered to a
+// check's webhook in order even when deliveries are slow.
+func TestConsulScript_WebhookOrder(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	payloads := make(chan checkWebhookPayload, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload checkWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("error decoding webhook payload: %v", err)
+		}
+		// Slow down the first delivery so later ones would overtake it
+		if payload.Output == "0" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		payloads <- payload
+	}))
+	defer ts.Close()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:     "test",
+		Interval: time.Hour,
+		Timeout:  3 * time.Second,
+		Webhook:  ts.URL,
+	}
+
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, newSimpleExec(0, nil), hb, nil, testlog.HCLogger(t), nil)
+	go check.deliverWebhooks()
+	defer check.stopWebhooks()
+
+	statuses := []string{api.HealthPassing, api.HealthCritical}
+	for i := 0; i < 5; i++ {
+		check.notifyWebhook(statuses[i%2], statuses[(i+1)%2], strconv.Itoa(i))
+	}
+
+	for i := 0; i < 5; i++ {
+		select {
+		case payload := <-payloads:
+			require.Equal(strconv.Itoa(i), payload.Output)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for webhook %d", i)
+		}
+	}
+}
+
+// TestConsulScript_StatusNames asserts a script check's StatusNames override
+// the status reported to Consul.
+func TestConsulScript_StatusNames(t *testing.T) {
+	t.Parallel()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:     "test",
+		Interval: time.Hour,
+		Timeout:  3 * time.Second,
+		StatusNames: map[string]string{
+			api.HealthWarning: "custom-warning",
+		},
+	}
+
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, newSimpleExec(1, nil), hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case update := <-hb.updates:
+		require.Equal(t, "custom-warning", update.status)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+}
+
+// TestConsulScript_AlignToClock asserts a script check aligned to the clock
+// first runs on a wall-clock multiple of its interval.
+func TestConsulScript_AlignToClock(t *testing.T) {
+	t.Parallel()
+
+	interval := 500 * time.Millisecond
+	serviceCheck := structs.ServiceCheck{
+		Name:         "test",
+		Interval:     interval,
+		Timeout:      3 * time.Second,
+		AlignToClock: true,
+	}
+
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, newSimpleExec(0, nil), hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case <-hb.updates:
+		now := time.Now()
+		offset := now.Sub(now.Truncate(interval))
+		require.True(t, offset < 150*time.Millisecond, "expected run near a %s boundary; ran %s after one", interval, offset)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+}
+
+func TestConsulScript_untilBoundary(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Duration(0), untilBoundary(base, time.Minute))
+	require.Equal(t, 45*time.Second, untilBoundary(base.Add(15*time.Second), time.Minute))
+	require.Equal(t, 4*time.Minute, untilBoundary(base.Add(time.Minute), 5*time.Minute))
+	require.Equal(t, time.Duration(0), untilBoundary(base.Add(time.Second), 0))
+}
+
+// erroringHeartbeater is a heartbeater whose updates always fail.
+type erroringHeartbeater struct {
+	calls int32
+}
+
+func (e *erroringHeartbeater) UpdateTTL(checkID, output, status string) error {
+	atomic.AddInt32(&e.calls, 1)
+	return fmt.Errorf("consul unavailable")
+}
+
+func (e *erroringHeartbeater) CheckDeregister(checkID string) error {
+	return fmt.Errorf("consul unavailable")
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestConsulScript_UpdateErrorDedupe asserts repeated identical errors
+// updating a check are only logged once.
+func TestConsulScript_UpdateErrorDedupe(t *testing.T) {
+	t.Parallel()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:     "test",
+		Interval: 10 * time.Millisecond,
+		Timeout:  3 * time.Second,
+	}
+
+	var logs lockedBuffer
+	logger := log.New(&log.LoggerOptions{
+		Output: &logs,
+		Level:  log.Trace,
+	})
+
+	hb := &erroringHeartbeater{}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, newSimpleExec(0, nil), hb, nil, logger, nil)
+	check.errSummaryInterval = time.Hour
+	handle := check.run()
+
+	testutil.WaitForResult(func() (bool, error) {
+		calls := atomic.LoadInt32(&hb.calls)
+		return calls >= 10, fmt.Errorf("expected at least 10 updates; got %d", calls)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	handle.cancel()
+	<-handle.wait()
+
+	out := logs.String()
+	require.Equal(t, 1, strings.Count(out, "consul unavailable"), out)
+	require.Equal(t, 1, strings.Count(out, "updating check failed"), out)
+}
+
+// TestConsulScript_UpdateErrorSummary asserts repeated identical errors
+// updating a check are periodically summarized.
+func TestConsulScript_UpdateErrorSummary(t *testing.T) {
+	t.Parallel()
+
+	var logs lockedBuffer
+	check := &scriptCheck{
+		errSummaryInterval: time.Minute,
+		logger: log.New(&log.LoggerOptions{
+			Output: &logs,
+			Level:  log.Trace,
+		}),
+	}
+
+	start := time.Now()
+	err := fmt.Errorf("consul unavailable")
+	for i := 0; i < 6; i++ {
+		check.logUpdateError(err, start.Add(time.Duration(i)*15*time.Second))
+	}
+
+	// Logged once when first seen and summarized a minute later
+	out := logs.String()
+	require.Equal(t, 1, strings.Count(out, "updating check failed"), out)
+	require.Equal(t, 1, strings.Count(out, "updating check still failing"), out)
+	require.Contains(t, out, "occurrences=4")
+	require.Equal(t, 1, check.errRepeats)
+
+	// A different error is logged immediately
+	check.logUpdateError(fmt.Errorf("permission denied"), start.Add(time.Hour))
+	require.Equal(t, 2, strings.Count(logs.String(), "updating check failed"))
+	require.Zero(t, check.errRepeats)
+}
+
+// optionsExec implements OptionsScriptExecutor by recording the options it
+// was given and returning fixed stdout and stderr.
+type optionsExec struct {
+	opts chan interfaces.ScriptExecOptions
+}
+
+func (o *optionsExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	return []byte("combined"), 1, nil
+}
+
+func (o *optionsExec) ExecWithOptions(_ time.Duration, _ string, _ []string, opts interfaces.ScriptExecOptions) ([]byte, []byte, int, error) {
+	o.opts <- opts
+	return []byte("some output\n"), []byte("boom\n"), 1, nil
+}
+
+// TestConsulScript_Exec_Options asserts a streaming script check with limits
+// and a kill grace passes all of them to executors supporting options and
+// still reports stderr.
+func TestConsulScript_Exec_Options(t *testing.T) {
+	t.Parallel()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:           "test",
+		Interval:       time.Hour,
+		Timeout:        3 * time.Second,
+		KillGrace:      time.Second,
+		StreamInterval: time.Second,
+		Nice:           10,
+		RlimitCPU:      5 * time.Second,
+		RlimitMemoryMB: 256,
+		SeparateStderr: true,
+		TruncateFrom:   structs.CheckTruncateHead,
+	}
+
+	hb := newFakeHeartbeater()
+	exec := &optionsExec{opts: make(chan interfaces.ScriptExecOptions, 1)}
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, exec, hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case update := <-hb.updates:
+		require.Equal(t, "some output\nstderr: boom\n", update.output)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+
+	opts := <-exec.opts
+	require.Equal(t, time.Second, opts.KillGrace)
+	expected := interfaces.ScriptLimits{
+		Nice:     10,
+		CPU:      5 * time.Second,
+		MemoryMB: 256,
+	}
+	require.Equal(t, expected, opts.Limits)
+	require.NotNil(t, opts.Output)
+	require.True(t, opts.SeparateStderr)
+	require.True(t, opts.KeepHead)
+}
+
+// TestConsulScript_Exec_OptionsUnsupported asserts a warning is logged when a
+// script check sets options its executor can't apply.
+func TestConsulScript_Exec_OptionsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:      "test",
+		Interval:  time.Hour,
+		Timeout:   3 * time.Second,
+		KillGrace: time.Second,
+	}
+
+	var logs lockedBuffer
+	logger := log.New(&log.LoggerOptions{
+		Output: &logs,
+		Level:  log.Trace,
+	})
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, simpleExec{code: 0}, hb, nil, logger, nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case update := <-hb.updates:
+		require.Equal(t, api.HealthPassing, update.status)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+	require.Contains(t, logs.String(), "not supported by the task driver")
+}
+
+// binaryExec is a ScriptExecutor that returns fixed, possibly binary output.
+type binaryExec struct {
+	output []byte
+}
+
+func (b binaryExec) Exec(time.Duration, string, []string) ([]byte, int, error) {
+	return b.output, 0, nil
+}
+
+// TestConsulScript_Base64Output asserts binary output of a script check is
+// base64 encoded before being reported.
+func TestConsulScript_Base64Output(t *testing.T) {
+	t.Parallel()
+
+	serviceCheck := structs.ServiceCheck{
+		Name:         "test",
+		Interval:     time.Hour,
+		Timeout:      3 * time.Second,
+		Base64Output: true,
+	}
+
+	raw := []byte{0xff, 0xfe, 0x00, 0x80, 'o', 'k', 0xc3}
+	require.False(t, utf8.Valid(raw))
+
+	hb := newFakeHeartbeater()
+	check := newScriptCheck("allocid", "testtask", "checkid", &serviceCheck, binaryExec{output: raw}, hb, nil, testlog.HCLogger(t), nil)
+	handle := check.run()
+	defer handle.cancel()
+
+	select {
+	case update := <-hb.updates:
+		require.Equal(t, api.HealthPassing, update.status)
+		decoded, err := base64.StdEncoding.DecodeString(update.output)
+		require.NoError(t, err)
+		require.Equal(t, raw, decoded)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for script check to exec")
+	}
+}
@@ -3,6 +3,7 @@ package consul
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"strings"
 	"sync/atomic"
@@ -836,6 +837,67 @@ func TestConsul_ShutdownOK(t *testing.T) {
 	}
 }
 
+// TestConsul_SetTracer asserts script checks registered by the ServiceClient
+// are traced by its Tracer.
+func TestConsul_SetTracer(t *testing.T) {
+	t.Parallel()
+	ctx := setupFake(t)
+	tracer := &fakeTracer{}
+	ctx.ServiceClient.SetTracer(tracer)
+
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "scriptcheck",
+			Type:     "script",
+			Command:  "true",
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		},
+	}
+
+	go ctx.ServiceClient.Run()
+	defer ctx.ServiceClient.Shutdown()
+	require.NoError(t, ctx.ServiceClient.RegisterTask(ctx.Task))
+
+	testutil.WaitForResult(func() (bool, error) {
+		for _, span := range tracer.Spans() {
+			if span.name == "script_check.heartbeat" && span.ended {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("expected a finished heartbeat span; got %v", tracer.Spans())
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+// TestConsul_RandSource asserts a fixed RandSource makes the jitter of retries
+// reproducible.
+func TestConsul_RandSource(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	backoffs := func() []time.Duration {
+		c := NewServiceClient(NewMockAgent(), testlog.HCLogger(t), true)
+		c.SetRandSource(rand.NewSource(42))
+		var out []time.Duration
+		for failures := 1; failures <= 10; failures++ {
+			backoff := c.retryBackoff(failures)
+			max := c.retryInterval * time.Duration(failures)
+			if max > c.maxRetryInterval {
+				max = c.maxRetryInterval
+			}
+			require.True(backoff <= max && backoff >= max-max/retryJitterFraction,
+				"backoff %v out of range for %v", backoff, max)
+			out = append(out, backoff)
+		}
+		return out
+	}
+
+	first := backoffs()
+	require.Equal(first, backoffs())
+}
+
 // TestConsul_ShutdownSlow tests the slow but ok path for the shutdown logic in
 // ServiceClient.
 func TestConsul_ShutdownSlow(t *testing.T) {
@@ -1723,3 +1785,527 @@ func TestGetAddress(t *testing.T) {
 		})
 	}
 }
+
+// TestConsul_ExportDefinitions asserts exported check definitions are the
+// definitions of the registered checks.
+func TestConsul_ExportDefinitions(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "c1",
+			Type:     "tcp",
+			Interval: time.Second,
+			Timeout:  time.Second,
+		},
+		{
+			Name:     "c2",
+			Type:     "http",
+			Path:     "/health",
+			Protocol: "http",
+			Interval: 10 * time.Second,
+			Timeout:  time.Second,
+		},
+	}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	defs := ctx.ServiceClient.ExportDefinitions()
+	require.Len(defs, 2)
+	require.Equal(*ctx.Task.Services[0].Checks[0], defs[0])
+	require.Equal(*ctx.Task.Services[0].Checks[1], defs[1])
+
+	// The exported definitions are copies
+	defs[0].Interval = time.Hour
+	require.Equal(time.Second, ctx.ServiceClient.ExportDefinitions()[0].Interval)
+	require.Equal(time.Second, ctx.Task.Services[0].Checks[0].Interval)
+
+	// Deregistering removes the definitions
+	ctx.ServiceClient.RemoveTask(ctx.Task)
+	require.NoError(ctx.syncOnce())
+	require.Empty(ctx.ServiceClient.ExportDefinitions())
+}
+
+// TestConsul_CheckWebhookAllowlist asserts script check webhooks are only sent
+// to hosts allowed by the agent.
+func TestConsul_CheckWebhookAllowlist(t *testing.T) {
+	t.Parallel()
+
+	webhooks := map[string]string{
+		"allowed":      "https://hooks.example.com/nomad",
+		"allowed-port": "http://10.0.0.5:8080/notify",
+		"other-port":   "http://10.0.0.5:9090/notify",
+		"denied":       "http://169.254.169.254/latest",
+	}
+	run := func(allowlist []string, expected map[string]bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+			ctx := setupFake(t)
+			ctx.ServiceClient.SetCheckWebhookAllowlist(allowlist)
+
+			ctx.Task.Services[0].Checks = nil
+			for name, webhook := range webhooks {
+				ctx.Task.Services[0].Checks = append(ctx.Task.Services[0].Checks, &structs.ServiceCheck{
+					Name:     name,
+					Type:     "script",
+					Command:  "true",
+					Interval: 9000 * time.Hour,
+					Timeout:  time.Second,
+					Webhook:  webhook,
+				})
+			}
+			require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+			require.NoError(ctx.syncOnce())
+
+			require.Len(ctx.ServiceClient.scripts, len(webhooks))
+			for _, script := range ctx.ServiceClient.scripts {
+				name := script.check.Name
+				if expected[name] {
+					require.Equal(webhooks[name], script.webhook, "check %q", name)
+				} else {
+					require.Empty(script.webhook, "check %q", name)
+				}
+			}
+		}
+	}
+
+	t.Run("Default", run(nil, map[string]bool{}))
+	t.Run("Hosts", run([]string{"HOOKS.example.com", "10.0.0.5:8080"}, map[string]bool{
+		"allowed":      true,
+		"allowed-port": true,
+	}))
+	t.Run("Any", run([]string{"*"}, map[string]bool{
+		"allowed":      true,
+		"allowed-port": true,
+		"other-port":   true,
+		"denied":       true,
+	}))
+}
+
+// TestConsul_MaxChecksPerAlloc asserts registrations exceeding the maximum
+// number of checks for an allocation are rejected.
+func TestConsul_MaxChecksPerAlloc(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+	ctx.ServiceClient.SetMaxChecksPerAlloc(2)
+
+	newCheck := func(name string) *structs.ServiceCheck {
+		return &structs.ServiceCheck{
+			Name:     name,
+			Type:     "tcp",
+			Interval: 10 * time.Second,
+			Timeout:  time.Second,
+		}
+	}
+
+	// Registering up to the limit succeeds
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{newCheck("check1"), newCheck("check2")}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+	require.Len(ctx.FakeConsul.checks, 2)
+
+	// Another task in the same allocation can't add more checks
+	task2 := ctx.Task.Copy()
+	task2.Name = "task2"
+	task2.Services[0].Checks = []*structs.ServiceCheck{newCheck("check3")}
+	err := ctx.ServiceClient.RegisterTask(task2)
+	require.Error(err)
+	require.Contains(err.Error(), "exceeds the limit of 2")
+
+	// Nor can the task itself grow beyond the limit
+	oldTask := ctx.Task.Copy()
+	ctx.Task.Services[0].Checks = append(ctx.Task.Services[0].Checks, newCheck("check3"))
+	err = ctx.ServiceClient.UpdateTask(oldTask, ctx.Task)
+	require.Error(err)
+	require.Contains(err.Error(), "exceeds the limit of 2")
+	require.Len(ctx.FakeConsul.checks, 2)
+
+	// Other allocations have their own limit
+	task3 := oldTask.Copy()
+	task3.AllocID = uuid.Generate()
+	require.NoError(ctx.ServiceClient.RegisterTask(task3))
+}
+
+// TestConsul_DedupeScriptChecks asserts identical deduplicated script checks
+// of an allocation share a single run whose results update every check.
+func TestConsul_DedupeScriptChecks(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	check := &structs.ServiceCheck{
+		Name:     "shared",
+		Type:     "script",
+		Command:  "true",
+		Interval: 9000 * time.Hour,
+		Timeout:  time.Second,
+		Dedupe:   true,
+	}
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{check}
+
+	task2 := testTask()
+	task2.AllocID = ctx.Task.AllocID
+	task2.Name = "taskname2"
+	task2.Services[0].Checks = []*structs.ServiceCheck{check.Copy()}
+	exec2 := task2.DriverExec.(*mockExec)
+
+	// Merge both registrations before syncing so both checks exist when
+	// the shared check first runs
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.ServiceClient.RegisterTask(task2))
+	ctx.ServiceClient.merge(<-ctx.ServiceClient.opCh)
+	ctx.ServiceClient.merge(<-ctx.ServiceClient.opCh)
+	require.NoError(ctx.ServiceClient.sync())
+	require.Len(ctx.ServiceClient.runningScripts, 1)
+
+	checkTTLs := func() map[string]int {
+		ctx.FakeConsul.mu.Lock()
+		defer ctx.FakeConsul.mu.Unlock()
+		ttls := make(map[string]int, len(ctx.FakeConsul.checkTTLs))
+		for id, n := range ctx.FakeConsul.checkTTLs {
+			ttls[id] = n
+		}
+		return ttls
+	}
+	testutil.WaitForResult(func() (bool, error) {
+		ttls := checkTTLs()
+		if len(ttls) != 2 {
+			return false, fmt.Errorf("expected 2 checks to be updated but found %d", len(ttls))
+		}
+		for id, n := range ttls {
+			if n != 1 {
+				return false, fmt.Errorf("expected 1 update of %q but found %d", id, n)
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The script ran once in only one of the tasks
+	require.Equal(1, len(ctx.MockExec.execs)+len(exec2.execs))
+
+	// Removing the task running the shared check hands it to the other task
+	running, remaining, remainingExec := ctx.Task, task2, exec2
+	if len(exec2.execs) == 1 {
+		running, remaining, remainingExec = task2, ctx.Task, ctx.MockExec
+	}
+	ctx.ServiceClient.RemoveTask(running)
+	require.NoError(ctx.syncOnce())
+	require.Len(ctx.ServiceClient.runningScripts, 1)
+
+	select {
+	case <-remainingExec.execs:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected task %q to run the shared check", remaining.Name)
+	}
+}
+
+// TestConsul_HasRun asserts HasRun reports whether a script check has been
+// executed.
+func TestConsul_HasRun(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	unblockCh := make(chan struct{})
+	ctx.MockExec.ExecFunc = func(ctx context.Context, cmd string, args []string) ([]byte, int, error) {
+		select {
+		case <-unblockCh:
+			return []byte("ok"), 0, nil
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "scriptcheck",
+			Type:     "script",
+			Command:  "true",
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		},
+	}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	regs := ctx.FakeConsul.CheckRegs()
+	require.Len(regs, 1)
+	checkID := regs[0].ID
+
+	// The first execution is still pending
+	require.False(ctx.ServiceClient.HasRun(checkID))
+	require.False(ctx.ServiceClient.HasRun("unknown"))
+
+	close(unblockCh)
+	testutil.WaitForResult(func() (bool, error) {
+		return ctx.ServiceClient.HasRun(checkID), fmt.Errorf("check has not run")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	ctx.ServiceClient.RemoveTask(ctx.Task)
+	require.NoError(ctx.syncOnce())
+	require.False(ctx.ServiceClient.HasRun(checkID))
+}
+
+// TestConsul_ChecksInMaintenance asserts every script check in maintenance is
+// returned with its status and reason.
+func TestConsul_ChecksInMaintenance(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "check1",
+			Type:     "script",
+			Command:  "true",
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		},
+		{
+			Name:     "check2",
+			Type:     "script",
+			Command:  "false",
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		},
+		{
+			Name:     "check3",
+			Type:     "script",
+			Command:  "echo",
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		},
+	}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+	require.Empty(ctx.ServiceClient.ChecksInMaintenance())
+
+	checkIDs := make(map[string]string)
+	for _, reg := range ctx.FakeConsul.CheckRegs() {
+		checkIDs[reg.Name] = reg.ID
+	}
+	require.Len(checkIDs, 3)
+
+	before := time.Now()
+	require.NoError(ctx.ServiceClient.runningScripts[checkIDs["check1"]].SetMaintenance(true, "", "upgrading"))
+	require.NoError(ctx.ServiceClient.runningScripts[checkIDs["check2"]].SetMaintenance(true, api.HealthWarning, "migrating"))
+
+	checks := ctx.ServiceClient.ChecksInMaintenance()
+	require.Len(checks, 2)
+	reasons := make(map[string]MaintenanceInfo)
+	for _, check := range checks {
+		require.False(check.Since.Before(before))
+		reasons[check.CheckID] = check
+	}
+	require.Equal(api.HealthPassing, reasons[checkIDs["check1"]].Status)
+	require.Equal("upgrading", reasons[checkIDs["check1"]].Reason)
+	require.Equal(api.HealthWarning, reasons[checkIDs["check2"]].Status)
+	require.Equal("migrating", reasons[checkIDs["check2"]].Reason)
+
+	// Leaving maintenance removes the check
+	require.NoError(ctx.ServiceClient.runningScripts[checkIDs["check1"]].SetMaintenance(false, "", ""))
+	checks = ctx.ServiceClient.ChecksInMaintenance()
+	require.Len(checks, 1)
+	require.Equal(checkIDs["check2"], checks[0].CheckID)
+}
+
+// TestConsul_ChecksForTask asserts only the checks of the requested task are
+// returned.
+func TestConsul_ChecksForTask(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "task1-b",
+			Type:     "tcp",
+			Interval: 10 * time.Second,
+			Timeout:  time.Second,
+		},
+		{
+			Name:     "task1-a",
+			Type:     "tcp",
+			Interval: 10 * time.Second,
+			Timeout:  time.Second,
+		},
+	}
+
+	task2 := testTask()
+	task2.AllocID = ctx.Task.AllocID
+	task2.Name = "taskname2"
+	task2.Services[0].Checks = []*structs.ServiceCheck{
+		{
+			Name:     "task2",
+			Type:     "tcp",
+			Interval: 10 * time.Second,
+			Timeout:  time.Second,
+		},
+	}
+
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+	require.NoError(ctx.ServiceClient.RegisterTask(task2))
+	require.NoError(ctx.syncOnce())
+
+	checks := ctx.ServiceClient.ChecksForTask(ctx.Task.AllocID, ctx.Task.Name)
+	require.Len(checks, 2)
+	require.Equal("task1-a", checks[0].Check.Name)
+	require.Equal("task1-b", checks[1].Check.Name)
+	for _, check := range checks {
+		require.Equal(ctx.Task.AllocID, check.AllocID)
+		require.Equal(ctx.Task.Name, check.TaskName)
+		require.NotEmpty(check.ID)
+	}
+
+	checks = ctx.ServiceClient.ChecksForTask(task2.AllocID, task2.Name)
+	require.Len(checks, 1)
+	require.Equal("task2", checks[0].Check.Name)
+
+	require.Empty(ctx.ServiceClient.ChecksForTask(ctx.Task.AllocID, "unknown"))
+	require.Empty(ctx.ServiceClient.ChecksForTask("unknown", ctx.Task.Name))
+}
+
+// TestConsul_UpdateTask_SyncChecks asserts updating a task's check definitions
+// only replaces the checks that were added, changed, or removed.
+func TestConsul_UpdateTask_SyncChecks(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+
+	scriptCheck := func(name, command string) *structs.ServiceCheck {
+		return &structs.ServiceCheck{
+			Name:     name,
+			Type:     "script",
+			Command:  command,
+			Interval: 9000 * time.Hour,
+			Timeout:  10 * time.Second,
+		}
+	}
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		scriptCheck("unchanged", "true"),
+		scriptCheck("changed", "true"),
+		scriptCheck("removed", "true"),
+	}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	checkIDs := func() map[string]string {
+		ids := make(map[string]string)
+		for _, reg := range ctx.FakeConsul.CheckRegs() {
+			ids[reg.Name] = reg.ID
+		}
+		return ids
+	}
+	before := checkIDs()
+	require.Len(before, 3)
+	unchangedHandle := ctx.ServiceClient.runningScripts[before["unchanged"]]
+	require.NotNil(unchangedHandle)
+
+	// Updating to the registered definitions replaces nothing
+	require.NoError(ctx.ServiceClient.UpdateTask(ctx.Task, ctx.Task))
+	require.NoError(ctx.syncOnce())
+	require.Equal(before, checkIDs())
+	require.True(unchangedHandle == ctx.ServiceClient.runningScripts[before["unchanged"]])
+
+	origTask := ctx.Task.Copy()
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		scriptCheck("unchanged", "true"),
+		scriptCheck("changed", "false"),
+		scriptCheck("added", "true"),
+	}
+	require.NoError(ctx.ServiceClient.UpdateTask(origTask, ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	after := checkIDs()
+	require.Len(after, 3)
+	require.Equal(before["unchanged"], after["unchanged"])
+	require.NotEqual(before["changed"], after["changed"])
+	require.NotContains(after, "removed")
+	require.Contains(after, "added")
+	require.Len(ctx.FakeConsul.services, 1)
+
+	// The unchanged check kept running while the others were replaced
+	require.Len(ctx.ServiceClient.runningScripts, 3)
+	require.True(unchangedHandle == ctx.ServiceClient.runningScripts[after["unchanged"]])
+	require.NotContains(ctx.ServiceClient.runningScripts, before["changed"])
+	require.NotContains(ctx.ServiceClient.runningScripts, before["removed"])
+	require.Contains(ctx.ServiceClient.runningScripts, after["changed"])
+	require.Contains(ctx.ServiceClient.runningScripts, after["added"])
+
+	reg, err := ctx.ServiceClient.AllocRegistrations(ctx.Task.AllocID)
+	require.NoError(err)
+	require.Equal(3, reg.NumChecks())
+
+	// Updating to the new definitions again replaces nothing
+	require.NoError(ctx.ServiceClient.UpdateTask(ctx.Task, ctx.Task))
+	require.NoError(ctx.syncOnce())
+	require.Equal(after, checkIDs())
+	require.True(unchangedHandle == ctx.ServiceClient.runningScripts[after["unchanged"]])
+}
+
+// TestConsul_StartOrder asserts script checks registered together start in
+// ascending StartOrder.
+func TestConsul_StartOrder(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := setupFake(t)
+	ctx.ServiceClient.checkStartStagger = 50 * time.Millisecond
+
+	type start struct {
+		cmd string
+		at  time.Time
+	}
+	starts := make(chan start, 10)
+	ctx.MockExec.ExecFunc = func(_ context.Context, cmd string, _ []string) ([]byte, int, error) {
+		starts <- start{cmd: cmd, at: time.Now()}
+		return []byte("ok"), 0, nil
+	}
+
+	scriptCheck := func(cmd string, order int) *structs.ServiceCheck {
+		return &structs.ServiceCheck{
+			Name:       cmd,
+			Type:       "script",
+			Command:    cmd,
+			Interval:   9000 * time.Hour,
+			Timeout:    10 * time.Second,
+			StartOrder: order,
+		}
+	}
+	ctx.Task.Services[0].Checks = []*structs.ServiceCheck{
+		scriptCheck("third", 3),
+		scriptCheck("first", 1),
+		scriptCheck("unordered", 0),
+		scriptCheck("second", 2),
+		scriptCheck("also-second", 2),
+	}
+	require.NoError(ctx.ServiceClient.RegisterTask(ctx.Task))
+	require.NoError(ctx.syncOnce())
+
+	var order []string
+	var last time.Time
+	for i := 0; i < 5; i++ {
+		select {
+		case s := <-starts:
+			order = append(order, s.cmd)
+			if s.cmd == "third" {
+				require.True(s.at.Sub(last) >= 40*time.Millisecond, "third started %v after the previous check", s.at.Sub(last))
+			}
+			last = s.at
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for checks to start; started %v", order)
+		}
+	}
+
+	require.Equal("unordered", order[0])
+	require.Equal("first", order[1])
+	require.ElementsMatch([]string{"second", "also-second"}, order[2:4])
+	require.Equal("third", order[4])
+}
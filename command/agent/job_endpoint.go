@@ -762,22 +762,37 @@ func ApiTaskToStructsTask(apiTask *api.Task, structsTask *structs.Task) {
 				structsTask.Services[i].Checks = make([]*structs.ServiceCheck, l)
 				for j, check := range service.Checks {
 					structsTask.Services[i].Checks[j] = &structs.ServiceCheck{
-						Name:          check.Name,
-						Type:          check.Type,
-						Command:       check.Command,
-						Args:          check.Args,
-						Path:          check.Path,
-						Protocol:      check.Protocol,
-						PortLabel:     check.PortLabel,
-						AddressMode:   check.AddressMode,
-						Interval:      check.Interval,
-						Timeout:       check.Timeout,
-						InitialStatus: check.InitialStatus,
-						TLSSkipVerify: check.TLSSkipVerify,
-						Header:        check.Header,
-						Method:        check.Method,
-						GRPCService:   check.GRPCService,
-						GRPCUseTLS:    check.GRPCUseTLS,
+						Name:            check.Name,
+						Type:            check.Type,
+						Command:         check.Command,
+						Args:            check.Args,
+						Path:            check.Path,
+						Protocol:        check.Protocol,
+						PortLabel:       check.PortLabel,
+						AddressMode:     check.AddressMode,
+						Interval:        check.Interval,
+						Timeout:         check.Timeout,
+						InitialStatus:   check.InitialStatus,
+						TLSSkipVerify:   check.TLSSkipVerify,
+						Header:          check.Header,
+						Method:          check.Method,
+						GRPCService:     check.GRPCService,
+						GRPCUseTLS:      check.GRPCUseTLS,
+						StreamInterval:  check.StreamInterval,
+						DefaultOutput:   check.DefaultOutput,
+						Dedupe:          check.Dedupe,
+						KillGrace:       check.KillGrace,
+						TruncateFrom:    check.TruncateFrom,
+						Webhook:         check.Webhook,
+						WebhookInterval: check.WebhookInterval,
+						StatusNames:     check.StatusNames,
+						AlignToClock:    check.AlignToClock,
+						Nice:            check.Nice,
+						RlimitCPU:       check.RlimitCPU,
+						RlimitMemoryMB:  check.RlimitMemoryMB,
+						Base64Output:    check.Base64Output,
+						StartOrder:      check.StartOrder,
+						SeparateStderr:  check.SeparateStderr,
 					}
 					if check.CheckRestart != nil {
 						structsTask.Services[i].Checks[j].CheckRestart = &structs.CheckRestart{
@@ -85,7 +85,8 @@ var (
 		FSIsolation: drivers.FSIsolationNone,
 	}
 
-	_ drivers.DriverPlugin = (*Driver)(nil)
+	_ drivers.DriverPlugin      = (*Driver)(nil)
+	_ drivers.ExecOptionsDriver = (*Driver)(nil)
 )
 
 func init() {
@@ -533,7 +534,15 @@ func (d *Driver) SignalTask(taskID string, signal string) error {
 }
 
 func (d *Driver) ExecTask(taskID string, cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
-	if len(cmd) == 0 {
+	return d.ExecTaskWithOptions(taskID, &drivers.ExecOptions{
+		Command: cmd,
+		Timeout: timeout,
+	})
+}
+
+// ExecTaskWithOptions runs a command in the task with the task's executor.
+func (d *Driver) ExecTaskWithOptions(taskID string, opts *drivers.ExecOptions) (*drivers.ExecTaskResult, error) {
+	if len(opts.Command) == 0 {
 		return nil, fmt.Errorf("error cmd must have at least one value")
 	}
 	handle, ok := d.tasks.Get(taskID)
@@ -541,17 +550,7 @@ func (d *Driver) ExecTask(taskID string, cmd []string, timeout time.Duration) (*
 		return nil, drivers.ErrTaskNotFound
 	}
 
-	out, exitCode, err := handle.exec.Exec(time.Now().Add(timeout), cmd[0], cmd[1:])
-	if err != nil {
-		return nil, err
-	}
-
-	return &drivers.ExecTaskResult{
-		Stdout: out,
-		ExitResult: &drivers.ExitResult{
-			ExitCode: exitCode,
-		},
-	}, nil
+	return executor.ExecTask(handle.exec, opts)
 }
 
 // GetAbsolutePath returns the absolute path of the passed binary by resolving
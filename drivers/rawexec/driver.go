@@ -501,7 +501,15 @@ func (d *Driver) SignalTask(taskID string, signal string) error {
 }
 
 func (d *Driver) ExecTask(taskID string, cmd []string, timeout time.Duration) (*drivers.ExecTaskResult, error) {
-	if len(cmd) == 0 {
+	return d.ExecTaskWithOptions(taskID, &drivers.ExecOptions{
+		Command: cmd,
+		Timeout: timeout,
+	})
+}
+
+// ExecTaskWithOptions runs a command in the task with the task's executor.
+func (d *Driver) ExecTaskWithOptions(taskID string, opts *drivers.ExecOptions) (*drivers.ExecTaskResult, error) {
+	if len(opts.Command) == 0 {
 		return nil, fmt.Errorf("error cmd must have at least one value")
 	}
 	handle, ok := d.tasks.Get(taskID)
@@ -509,15 +517,5 @@ func (d *Driver) ExecTask(taskID string, cmd []string, timeout time.Duration) (*
 		return nil, drivers.ErrTaskNotFound
 	}
 
-	out, exitCode, err := handle.exec.Exec(time.Now().Add(timeout), cmd[0], cmd[1:])
-	if err != nil {
-		return nil, err
-	}
-
-	return &drivers.ExecTaskResult{
-		Stdout: out,
-		ExitResult: &drivers.ExitResult{
-			ExitCode: exitCode,
-		},
-	}, nil
+	return executor.ExecTask(handle.exec, opts)
 }
@@ -14,6 +14,8 @@ import (
 	cstructs "github.com/hashicorp/nomad/client/structs"
 	"github.com/hashicorp/nomad/drivers/shared/executor/proto"
 	"github.com/hashicorp/nomad/plugins/drivers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var _ Executor = (*grpcExecutorClient)(nil)
@@ -114,6 +116,32 @@ func (c *grpcExecutorClient) Stats(ctx context.Context, interval time.Duration)
 	return ch, nil
 }
 
+// execStreaming runs a command with the ExecStreaming RPC, calling output
+// with each chunk of output received, and returns its result.
+func (c *grpcExecutorClient) execStreaming(ctx context.Context, req *proto.ExecRequest, output func([]byte)) (*proto.ExecResponse, error) {
+	stream, err := c.client.ExecStreaming(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("executor closed the stream without a result")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.Output) > 0 {
+			output(resp.Output)
+		}
+		if resp.Result != nil {
+			return resp.Result, nil
+		}
+	}
+}
+
 func (c *grpcExecutorClient) handleStats(ctx context.Context, stream proto.Executor_StatsClient, ch chan<- *cstructs.TaskResourceUsage) {
 	defer close(ch)
 	for {
@@ -163,21 +191,62 @@ func (c *grpcExecutorClient) Signal(s os.Signal) error {
 }
 
 func (c *grpcExecutorClient) Exec(deadline time.Time, cmd string, args []string) ([]byte, int, error) {
+	res, err := c.ExecWithOptions(deadline, cmd, args, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return res.Output, res.ExitCode, nil
+}
+
+func (c *grpcExecutorClient) ExecWithOptions(deadline time.Time, cmd string, args []string, opts *ExecOptions) (*ExecResult, error) {
+	if opts == nil {
+		opts = &ExecOptions{}
+	}
+
 	ctx := context.Background()
 	pbDeadline, err := ptypes.TimestampProto(deadline)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	req := &proto.ExecRequest{
-		Deadline: pbDeadline,
-		Cmd:      cmd,
-		Args:     args,
+		Deadline:       pbDeadline,
+		Cmd:            cmd,
+		Args:           args,
+		KillGrace:      int64(opts.KillGrace),
+		SeparateStderr: opts.SeparateStderr,
+		KeepHead:       opts.KeepHead,
+	}
+	if limits := opts.Limits; limits != nil {
+		req.Limits = &proto.ExecLimits{
+			Nice:     int32(limits.Nice),
+			Cpu:      int64(limits.CPU),
+			MemoryMb: int32(limits.MemoryMB),
+		}
 	}
 
-	resp, err := c.client.Exec(ctx, req)
+	var resp *proto.ExecResponse
+	if opts.Output != nil {
+		resp, err = c.execStreaming(ctx, req, opts.Output)
+		if status.Code(err) == codes.Unimplemented {
+			// Executors started by older clients can't stream output
+			c.logger.Warn("executor doesn't support streaming exec output; only reporting final output")
+			resp, err = c.client.Exec(ctx, req)
+		}
+	} else {
+		resp, err = c.client.Exec(ctx, req)
+	}
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	return resp.Output, int(resp.ExitCode), nil
+	res := &ExecResult{
+		Output:   resp.Output,
+		Stderr:   resp.Stderr,
+		ExitCode: int(resp.ExitCode),
+	}
+	if resp.DeadlineExceeded {
+		return res, context.DeadlineExceeded
+	}
+	return res, nil
 }
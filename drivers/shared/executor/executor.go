@@ -77,6 +77,95 @@ type Executor interface {
 	// Exec executes the given command and args inside the executor context
 	// and returns the output and exit code.
 	Exec(deadline time.Time, cmd string, args []string) ([]byte, int, error)
+
+	// ExecWithOptions is like Exec but the command is run as configured by
+	// opts, which may be nil. A command given a grace period to exit after
+	// the deadline returns context.DeadlineExceeded along with its result.
+	ExecWithOptions(deadline time.Time, cmd string, args []string, opts *ExecOptions) (*ExecResult, error)
+}
+
+// ExecOptions configure how ExecWithOptions runs a command. The zero value
+// runs it like Exec.
+type ExecOptions struct {
+	// KillGrace is how long a command still running at the deadline is
+	// given to exit after SIGTERM before it's killed. If zero it's killed
+	// immediately.
+	KillGrace time.Duration
+
+	// Limits lower the priority of and bound the resources used by the
+	// command. They're applied before the command runs.
+	Limits *ScriptLimits
+
+	// Output, if set, is called with each chunk of output as the command
+	// produces it. The full output is still returned once the command exits.
+	Output func([]byte)
+
+	// SeparateStderr captures stderr separately from the output, which then
+	// only holds stdout.
+	SeparateStderr bool
+
+	// KeepHead keeps the beginning of output longer than
+	// drivers.CheckBufSize rather than the end.
+	KeepHead bool
+}
+
+// outputWriter calls the function with a copy of each write so it can
+// retain the chunk.
+type outputWriter func([]byte)
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w(append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// outputBuffer captures the output of a command run by ExecWithOptions.
+type outputBuffer interface {
+	io.Writer
+	Bytes() []byte
+}
+
+// newOutputBuffer returns a buffer holding drivers.CheckBufSize bytes of
+// output: the beginning if keepHead is set, otherwise the end.
+func newOutputBuffer(keepHead bool) outputBuffer {
+	if keepHead {
+		return &headBuffer{size: drivers.CheckBufSize}
+	}
+	buf, _ := circbuf.NewBuffer(int64(drivers.CheckBufSize))
+	return buf
+}
+
+// headBuffer keeps the first size bytes written to it and discards the rest.
+type headBuffer struct {
+	buf  []byte
+	size int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if n := b.size - len(b.buf); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		b.buf = append(b.buf, p[:n]...)
+	}
+	return len(p), nil
+}
+
+func (b *headBuffer) Bytes() []byte {
+	return b.buf
+}
+
+// ExecResult is the result of a command run by ExecWithOptions.
+type ExecResult struct {
+	// Output is the combined stdout and stderr of the command, truncated to
+	// drivers.CheckBufSize as configured by ExecOptions.KeepHead
+	Output []byte
+
+	// Stderr is the stderr of the command, truncated to drivers.CheckBufSize,
+	// if it was captured separately
+	Stderr []byte
+
+	// ExitCode is the exit code of the command
+	ExitCode int
 }
 
 // ExecCommand holds the user command, args, and other isolation related
@@ -314,16 +403,61 @@ func (e *UniversalExecutor) Launch(command *ExecCommand) (*ProcessState, error)
 
 // Exec a command inside a container for exec and java drivers.
 func (e *UniversalExecutor) Exec(deadline time.Time, name string, args []string) ([]byte, int, error) {
+	res, err := e.ExecWithOptions(deadline, name, args, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Output, res.ExitCode, nil
+}
+
+// ExecWithOptions is like Exec but the command is run as configured by opts.
+// Limits are ignored with a warning on platforms that don't support them.
+func (e *UniversalExecutor) ExecWithOptions(deadline time.Time, name string, args []string, opts *ExecOptions) (*ExecResult, error) {
+	if opts == nil {
+		opts = &ExecOptions{}
+	}
 	ctx, cancel := context.WithDeadline(context.Background(), deadline)
 	defer cancel()
-	return ExecScript(ctx, e.childCmd.Dir, e.commandCfg.Env, e.childCmd.SysProcAttr, name, args)
+
+	if !opts.Limits.isZero() && !scriptLimitsSupported {
+		e.logger.Warn("script limits are not supported on this platform; ignoring", "command", name)
+	}
+	return execScript(ctx, opts, e.childCmd.Dir, e.commandCfg.Env, e.childCmd.SysProcAttr, name, args)
 }
 
-// ExecScript executes cmd with args and returns the output, exit code, and
-// error. Output is truncated to drivers/shared/structs.CheckBufSize
-func ExecScript(ctx context.Context, dir string, env []string, attrs *syscall.SysProcAttr,
-	name string, args []string) ([]byte, int, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+// ScriptLimits lower the priority of and bound the resources used by a
+// script. Zero values leave the script's priority or resource unchanged.
+type ScriptLimits struct {
+	// Nice is the scheduling priority the script runs at
+	Nice int
+
+	// CPU is the CPU time the script may use before it's signaled to exit
+	CPU time.Duration
+
+	// MemoryMB is the address space in MB the script may use
+	MemoryMB int
+}
+
+func (l *ScriptLimits) isZero() bool {
+	return l == nil || (l.Nice == 0 && l.CPU == 0 && l.MemoryMB == 0)
+}
+
+// execScript runs a script as configured by opts. A result is returned along
+// with ctx's error if the script was given a grace period to exit.
+func execScript(ctx context.Context, opts *ExecOptions, dir string,
+	env []string, attrs *syscall.SysProcAttr, name string, args []string) (*ExecResult, error) {
+	killGrace, limits := opts.KillGrace, opts.Limits
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Args[0] = name
+	if !limits.isZero() {
+		if err := limitScriptCommand(cmd, limits); err != nil {
+			return nil, err
+		}
+	}
 
 	// Copy runtime environment from the main command
 	cmd.SysProcAttr = attrs
@@ -331,16 +465,68 @@ func ExecScript(ctx context.Context, dir string, env []string, attrs *syscall.Sy
 	cmd.Env = env
 
 	// Capture output
-	buf, _ := circbuf.NewBuffer(int64(drivers.CheckBufSize))
-	cmd.Stdout = buf
-	cmd.Stderr = buf
+	buf := newOutputBuffer(opts.KeepHead)
+	var stdout io.Writer = buf
+	if opts.Output != nil {
+		stdout = io.MultiWriter(buf, outputWriter(opts.Output))
+	}
+	var stderrBuf outputBuffer
+	cmd.Stdout = stdout
+	if opts.SeparateStderr {
+		stderrBuf = newOutputBuffer(opts.KeepHead)
+		cmd.Stderr = stderrBuf
+	} else {
+		// Both streams share a writer so output is streamed in order
+		cmd.Stderr = stdout
+	}
+	result := func(exitCode int) *ExecResult {
+		res := &ExecResult{Output: buf.Bytes(), ExitCode: exitCode}
+		if stderrBuf != nil {
+			res.Stderr = stderrBuf.Bytes()
+		}
+		return res
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// Stop the script once the context is done
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-doneCh:
+			return
+		case <-ctx.Done():
+		}
 
-	if err := cmd.Run(); err != nil {
+		if killGrace > 0 {
+			if err := terminateScript(cmd.Process); err == nil {
+				select {
+				case <-doneCh:
+					return
+				case <-time.After(killGrace):
+				}
+			}
+		}
+		cmd.Process.Kill()
+	}()
+
+	err = cmd.Wait()
+	close(doneCh)
+	if killGrace > 0 && ctx.Err() != nil {
+		exitCode := -1
+		if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok {
+			exitCode = status.ExitStatus()
+		}
+		return result(exitCode), ctx.Err()
+	}
+	if err != nil {
 		exitErr, ok := err.(*exec.ExitError)
 		if !ok {
 			// Non-exit error, return it and let the caller treat
 			// it as a critical failure
-			return nil, 0, err
+			return nil, err
 		}
 
 		// Some kind of error happened; default to critical
@@ -351,9 +537,9 @@ func ExecScript(ctx context.Context, dir string, env []string, attrs *syscall.Sy
 
 		// Don't return the exitError as the caller only needs the
 		// output and code.
-		return buf.Bytes(), exitCode, nil
+		return result(exitCode), nil
 	}
-	return buf.Bytes(), 0, nil
+	return result(0), nil
 }
 
 // Wait waits until a process has exited and returns it's exitcode and errors
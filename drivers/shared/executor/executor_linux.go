@@ -5,6 +5,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -13,7 +14,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/armon/circbuf"
 	"github.com/hashicorp/consul-template/signals"
 	hclog "github.com/hashicorp/go-hclog"
 	multierror "github.com/hashicorp/go-multierror"
@@ -461,47 +461,106 @@ func (l *LibcontainerExecutor) Signal(s os.Signal) error {
 
 // Exec starts an additional process inside the container
 func (l *LibcontainerExecutor) Exec(deadline time.Time, cmd string, args []string) ([]byte, int, error) {
+	res, err := l.ExecWithOptions(deadline, cmd, args, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Output, res.ExitCode, nil
+}
+
+// ExecWithOptions is like Exec but the process is run as configured by opts.
+// Limits are applied before the process runs: its resource limits by the
+// container and its priority by starting it from a thread with that priority.
+func (l *LibcontainerExecutor) ExecWithOptions(deadline time.Time, cmd string, args []string, opts *ExecOptions) (*ExecResult, error) {
+	if opts == nil {
+		opts = &ExecOptions{}
+	}
+	killGrace, limits := opts.KillGrace, opts.Limits
+
 	combined := append([]string{cmd}, args...)
 	// Capture output
-	buf, _ := circbuf.NewBuffer(int64(drivers.CheckBufSize))
+	buf := newOutputBuffer(opts.KeepHead)
 
+	var stdout io.Writer = buf
+	if opts.Output != nil {
+		stdout = io.MultiWriter(buf, outputWriter(opts.Output))
+	}
 	process := &libcontainer.Process{
 		Args:   combined,
 		Env:    l.command.Env,
-		Stdout: buf,
-		Stderr: buf,
+		Stdout: stdout,
+		// Both streams share a writer so output is streamed in order
+		Stderr: stdout,
+	}
+	var stderrBuf outputBuffer
+	if opts.SeparateStderr {
+		stderrBuf = newOutputBuffer(opts.KeepHead)
+		process.Stderr = stderrBuf
+	}
+	execResult := func(exitCode int) *ExecResult {
+		res := &ExecResult{Output: buf.Bytes(), ExitCode: exitCode}
+		if stderrBuf != nil {
+			res.Stderr = stderrBuf.Bytes()
+		}
+		return res
+	}
+	if !limits.isZero() {
+		process.Rlimits = scriptRlimits(limits)
 	}
 
-	err := l.container.Run(process)
+	run := func() error { return l.container.Run(process) }
+	var err error
+	if !limits.isZero() && limits.Nice != 0 {
+		err = withPriority(limits.Nice, run)
+	} else {
+		err = run()
+	}
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	waitCh := make(chan *waitResult)
-	defer close(waitCh)
+	// Buffered so the wait doesn't block if the process is abandoned
+	waitCh := make(chan *waitResult, 1)
 	go l.handleExecWait(waitCh, process)
 
+	var result *waitResult
+	var timedOut bool
 	select {
-	case result := <-waitCh:
-		ps := result.ps
-		if result.err != nil {
-			if exitErr, ok := result.err.(*exec.ExitError); ok {
-				ps = exitErr.ProcessState
-			} else {
-				return nil, 0, result.err
-			}
-		}
-		var exitCode int
-		if status, ok := ps.Sys().(syscall.WaitStatus); ok {
-			exitCode = status.ExitStatus()
+	case result = <-waitCh:
+	case <-time.After(time.Until(deadline)):
+		if killGrace <= 0 {
+			process.Signal(os.Kill)
+			return nil, context.DeadlineExceeded
 		}
-		return buf.Bytes(), exitCode, nil
 
-	case <-time.After(time.Until(deadline)):
-		process.Signal(os.Kill)
-		return nil, 0, context.DeadlineExceeded
+		// Give the process a chance to clean up before killing it
+		timedOut = true
+		process.Signal(syscall.SIGTERM)
+		select {
+		case result = <-waitCh:
+		case <-time.After(killGrace):
+			process.Signal(os.Kill)
+			return execResult(-1), context.DeadlineExceeded
+		}
 	}
 
+	ps := result.ps
+	if result.err != nil {
+		if exitErr, ok := result.err.(*exec.ExitError); ok {
+			ps = exitErr.ProcessState
+		} else {
+			return nil, result.err
+		}
+	}
+	var exitCode int
+	if status, ok := ps.Sys().(syscall.WaitStatus); ok {
+		exitCode = status.ExitStatus()
+	}
+	res := execResult(exitCode)
+	if timedOut {
+		return res, context.DeadlineExceeded
+	}
+	return res, nil
 }
 
 type waitResult struct {
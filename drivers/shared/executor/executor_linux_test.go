@@ -267,3 +267,59 @@ func TestExecutor_cmdMounts(t *testing.T) {
 
 	require.EqualValues(t, expected, cmdMounts(input))
 }
+
+func TestExecScript_KillGraceTerm(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// The script cleans up and exits when sent SIGTERM
+	script := `trap 'echo cleaning up; exit 0' TERM; echo started; while true; do sleep 0.05; done`
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	opts := &ExecOptions{KillGrace: 5 * time.Second}
+	res, err := execScript(ctx, opts, "", nil, nil, "/bin/sh", []string{"-c", script})
+	elapsed := time.Since(start)
+
+	require.Equal(context.DeadlineExceeded, err)
+	require.Equal("started\ncleaning up\n", string(res.Output))
+	require.True(elapsed < 5*time.Second, "script was killed after %v instead of exiting on SIGTERM", elapsed)
+}
+
+func TestExecScript_Limits(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	script := `nice; ulimit -t`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	limits := &ScriptLimits{
+		Nice: 10,
+		CPU:  30 * time.Second,
+	}
+	res, err := execScript(ctx, &ExecOptions{Limits: limits}, "", nil, nil, "/bin/sh", []string{"-c", script})
+	require.NoError(err)
+	require.Zero(res.ExitCode)
+	require.Equal("10\n30\n", string(res.Output))
+}
+
+func TestExecScript_KillGraceKill(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// The script ignores SIGTERM so it's killed once the grace period ends
+	script := `trap '' TERM; while true; do sleep 0.05; done`
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	grace := 500 * time.Millisecond
+	start := time.Now()
+	res, err := execScript(ctx, &ExecOptions{KillGrace: grace}, "", nil, nil, "/bin/sh", []string{"-c", script})
+	elapsed := time.Since(start)
+
+	require.Equal(context.DeadlineExceeded, err)
+	require.Equal(-1, res.ExitCode)
+	require.True(elapsed >= 100*time.Millisecond+grace, "script was killed after %v before the grace period ended", elapsed)
+}
@@ -337,6 +337,44 @@ func TestExecutor_Start_Kill(pt *testing.T) {
 	}
 }
 
+func TestExecutor_ExecWithOptions_KeepHead(pt *testing.T) {
+	pt.Parallel()
+	for name, factory := range executorFactories {
+		pt.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			testExecCmd := testExecutorCommand(t)
+			execCmd, allocDir := testExecCmd.command, testExecCmd.allocDir
+			execCmd.Cmd = "/bin/sleep"
+			execCmd.Args = []string{"10"}
+			factory.configureExecCmd(t, execCmd)
+
+			defer allocDir.Destroy()
+			executor := factory.new(testlog.HCLogger(t))
+			defer executor.Shutdown("", 0)
+
+			ps, err := executor.Launch(execCmd)
+			require.NoError(err)
+			require.NotZero(ps.Pid)
+
+			// The script writes more output than the buffer holds
+			script := `echo head; i=0; while [ $i -lt 1000 ]; do echo xxxxxxxxx; i=$((i+1)); done; echo tail`
+			deadline := time.Now().Add(10 * time.Second)
+
+			res, err := executor.ExecWithOptions(deadline, "/bin/sh", []string{"-c", script}, &ExecOptions{KeepHead: true})
+			require.NoError(err)
+			require.Zero(res.ExitCode)
+			require.Len(res.Output, drivers.CheckBufSize)
+			require.True(strings.HasPrefix(string(res.Output), "head\n"), "output doesn't start with head: %q", res.Output[:10])
+
+			res, err = executor.ExecWithOptions(deadline, "/bin/sh", []string{"-c", script}, nil)
+			require.NoError(err)
+			require.Zero(res.ExitCode)
+			require.Len(res.Output, drivers.CheckBufSize)
+			require.True(strings.HasSuffix(string(res.Output), "tail\n"), "output doesn't end with tail: %q", res.Output[len(res.Output)-10:])
+		})
+	}
+}
+
 func TestExecutor_Shutdown_Exit(t *testing.T) {
 	require := require.New(t)
 	t.Parallel()
@@ -43,3 +43,9 @@ func (e *UniversalExecutor) shutdownProcess(sig os.Signal, proc *os.Process) err
 
 	return nil
 }
+
+// terminateScript asks a timed out script check to exit with SIGTERM so it
+// can clean up before being killed.
+func terminateScript(proc *os.Process) error {
+	return proc.Signal(syscall.SIGTERM)
+}
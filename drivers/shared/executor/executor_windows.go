@@ -67,3 +67,9 @@ func (e *UniversalExecutor) shutdownProcess(_ os.Signal, proc *os.Process) error
 
 	return nil
 }
+
+// terminateScript kills a timed out script check immediately as Windows has
+// no SIGTERM to let it clean up.
+func terminateScript(proc *os.Process) error {
+	return proc.Kill()
+}
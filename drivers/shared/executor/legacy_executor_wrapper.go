@@ -133,6 +133,18 @@ func (l *legacyExecutorWrapper) Exec(deadline time.Time, cmd string, args []stri
 	return l.client.Exec(deadline, cmd, args)
 }
 
+// ExecWithOptions ignores opts as executors from before 0.9 can only Exec.
+func (l *legacyExecutorWrapper) ExecWithOptions(deadline time.Time, cmd string, args []string, opts *ExecOptions) (*ExecResult, error) {
+	if opts != nil && (opts.KillGrace != 0 || opts.Limits != nil || opts.Output != nil) {
+		l.logger.Warn("executors from before 0.9 don't support exec options; ignoring kill grace, limits, and streaming")
+	}
+	output, exitCode, err := l.client.Exec(deadline, cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecResult{Output: output, ExitCode: exitCode}, nil
+}
+
 type pre09ExecutorRPC struct {
 	client *rpc.Client
 	logger hclog.Logger
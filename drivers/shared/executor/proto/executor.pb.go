@@ -47,7 +47,7 @@ func (m *LaunchRequest) Reset()         { *m = LaunchRequest{} }
 func (m *LaunchRequest) String() string { return proto.CompactTextString(m) }
 func (*LaunchRequest) ProtoMessage()    {}
 func (*LaunchRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{0}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{0}
 }
 func (m *LaunchRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_LaunchRequest.Unmarshal(m, b)
@@ -162,7 +162,7 @@ func (m *LaunchResponse) Reset()         { *m = LaunchResponse{} }
 func (m *LaunchResponse) String() string { return proto.CompactTextString(m) }
 func (*LaunchResponse) ProtoMessage()    {}
 func (*LaunchResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{1}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{1}
 }
 func (m *LaunchResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_LaunchResponse.Unmarshal(m, b)
@@ -199,7 +199,7 @@ func (m *WaitRequest) Reset()         { *m = WaitRequest{} }
 func (m *WaitRequest) String() string { return proto.CompactTextString(m) }
 func (*WaitRequest) ProtoMessage()    {}
 func (*WaitRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{2}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{2}
 }
 func (m *WaitRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_WaitRequest.Unmarshal(m, b)
@@ -230,7 +230,7 @@ func (m *WaitResponse) Reset()         { *m = WaitResponse{} }
 func (m *WaitResponse) String() string { return proto.CompactTextString(m) }
 func (*WaitResponse) ProtoMessage()    {}
 func (*WaitResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{3}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{3}
 }
 func (m *WaitResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_WaitResponse.Unmarshal(m, b)
@@ -269,7 +269,7 @@ func (m *ShutdownRequest) Reset()         { *m = ShutdownRequest{} }
 func (m *ShutdownRequest) String() string { return proto.CompactTextString(m) }
 func (*ShutdownRequest) ProtoMessage()    {}
 func (*ShutdownRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{4}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{4}
 }
 func (m *ShutdownRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ShutdownRequest.Unmarshal(m, b)
@@ -313,7 +313,7 @@ func (m *ShutdownResponse) Reset()         { *m = ShutdownResponse{} }
 func (m *ShutdownResponse) String() string { return proto.CompactTextString(m) }
 func (*ShutdownResponse) ProtoMessage()    {}
 func (*ShutdownResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{5}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{5}
 }
 func (m *ShutdownResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ShutdownResponse.Unmarshal(m, b)
@@ -344,7 +344,7 @@ func (m *UpdateResourcesRequest) Reset()         { *m = UpdateResourcesRequest{}
 func (m *UpdateResourcesRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateResourcesRequest) ProtoMessage()    {}
 func (*UpdateResourcesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{6}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{6}
 }
 func (m *UpdateResourcesRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateResourcesRequest.Unmarshal(m, b)
@@ -381,7 +381,7 @@ func (m *UpdateResourcesResponse) Reset()         { *m = UpdateResourcesResponse
 func (m *UpdateResourcesResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateResourcesResponse) ProtoMessage()    {}
 func (*UpdateResourcesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{7}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{7}
 }
 func (m *UpdateResourcesResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateResourcesResponse.Unmarshal(m, b)
@@ -411,7 +411,7 @@ func (m *VersionRequest) Reset()         { *m = VersionRequest{} }
 func (m *VersionRequest) String() string { return proto.CompactTextString(m) }
 func (*VersionRequest) ProtoMessage()    {}
 func (*VersionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{8}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{8}
 }
 func (m *VersionRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_VersionRequest.Unmarshal(m, b)
@@ -442,7 +442,7 @@ func (m *VersionResponse) Reset()         { *m = VersionResponse{} }
 func (m *VersionResponse) String() string { return proto.CompactTextString(m) }
 func (*VersionResponse) ProtoMessage()    {}
 func (*VersionResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{9}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{9}
 }
 func (m *VersionResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_VersionResponse.Unmarshal(m, b)
@@ -480,7 +480,7 @@ func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
 func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
 func (*StatsRequest) ProtoMessage()    {}
 func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{10}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{10}
 }
 func (m *StatsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StatsRequest.Unmarshal(m, b)
@@ -518,7 +518,7 @@ func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
 func (m *StatsResponse) String() string { return proto.CompactTextString(m) }
 func (*StatsResponse) ProtoMessage()    {}
 func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{11}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{11}
 }
 func (m *StatsResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StatsResponse.Unmarshal(m, b)
@@ -556,7 +556,7 @@ func (m *SignalRequest) Reset()         { *m = SignalRequest{} }
 func (m *SignalRequest) String() string { return proto.CompactTextString(m) }
 func (*SignalRequest) ProtoMessage()    {}
 func (*SignalRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{12}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{12}
 }
 func (m *SignalRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SignalRequest.Unmarshal(m, b)
@@ -593,7 +593,7 @@ func (m *SignalResponse) Reset()         { *m = SignalResponse{} }
 func (m *SignalResponse) String() string { return proto.CompactTextString(m) }
 func (*SignalResponse) ProtoMessage()    {}
 func (*SignalResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{13}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{13}
 }
 func (m *SignalResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SignalResponse.Unmarshal(m, b)
@@ -617,6 +617,10 @@ type ExecRequest struct {
 	Deadline             *timestamp.Timestamp `protobuf:"bytes,1,opt,name=deadline,proto3" json:"deadline,omitempty"`
 	Cmd                  string               `protobuf:"bytes,2,opt,name=cmd,proto3" json:"cmd,omitempty"`
 	Args                 []string             `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	KillGrace            int64                `protobuf:"varint,4,opt,name=kill_grace,json=killGrace,proto3" json:"kill_grace,omitempty"`
+	Limits               *ExecLimits          `protobuf:"bytes,5,opt,name=limits,proto3" json:"limits,omitempty"`
+	SeparateStderr       bool                 `protobuf:"varint,6,opt,name=separate_stderr,json=separateStderr,proto3" json:"separate_stderr,omitempty"`
+	KeepHead             bool                 `protobuf:"varint,7,opt,name=keep_head,json=keepHead,proto3" json:"keep_head,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -626,7 +630,7 @@ func (m *ExecRequest) Reset()         { *m = ExecRequest{} }
 func (m *ExecRequest) String() string { return proto.CompactTextString(m) }
 func (*ExecRequest) ProtoMessage()    {}
 func (*ExecRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{14}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{14}
 }
 func (m *ExecRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecRequest.Unmarshal(m, b)
@@ -667,9 +671,93 @@ func (m *ExecRequest) GetArgs() []string {
 	return nil
 }
 
+func (m *ExecRequest) GetKillGrace() int64 {
+	if m != nil {
+		return m.KillGrace
+	}
+	return 0
+}
+
+func (m *ExecRequest) GetLimits() *ExecLimits {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+func (m *ExecRequest) GetSeparateStderr() bool {
+	if m != nil {
+		return m.SeparateStderr
+	}
+	return false
+}
+
+func (m *ExecRequest) GetKeepHead() bool {
+	if m != nil {
+		return m.KeepHead
+	}
+	return false
+}
+
+type ExecLimits struct {
+	Nice                 int32    `protobuf:"varint,1,opt,name=nice,proto3" json:"nice,omitempty"`
+	Cpu                  int64    `protobuf:"varint,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	MemoryMb             int32    `protobuf:"varint,3,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExecLimits) Reset()         { *m = ExecLimits{} }
+func (m *ExecLimits) String() string { return proto.CompactTextString(m) }
+func (*ExecLimits) ProtoMessage()    {}
+func (*ExecLimits) Descriptor() ([]byte, []int) {
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{15}
+}
+func (m *ExecLimits) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecLimits.Unmarshal(m, b)
+}
+func (m *ExecLimits) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecLimits.Marshal(b, m, deterministic)
+}
+func (dst *ExecLimits) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecLimits.Merge(dst, src)
+}
+func (m *ExecLimits) XXX_Size() int {
+	return xxx_messageInfo_ExecLimits.Size(m)
+}
+func (m *ExecLimits) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecLimits.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecLimits proto.InternalMessageInfo
+
+func (m *ExecLimits) GetNice() int32 {
+	if m != nil {
+		return m.Nice
+	}
+	return 0
+}
+
+func (m *ExecLimits) GetCpu() int64 {
+	if m != nil {
+		return m.Cpu
+	}
+	return 0
+}
+
+func (m *ExecLimits) GetMemoryMb() int32 {
+	if m != nil {
+		return m.MemoryMb
+	}
+	return 0
+}
+
 type ExecResponse struct {
 	Output               []byte   `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
 	ExitCode             int32    `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	DeadlineExceeded     bool     `protobuf:"varint,3,opt,name=deadline_exceeded,json=deadlineExceeded,proto3" json:"deadline_exceeded,omitempty"`
+	Stderr               []byte   `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -679,7 +767,7 @@ func (m *ExecResponse) Reset()         { *m = ExecResponse{} }
 func (m *ExecResponse) String() string { return proto.CompactTextString(m) }
 func (*ExecResponse) ProtoMessage()    {}
 func (*ExecResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{15}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{16}
 }
 func (m *ExecResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ExecResponse.Unmarshal(m, b)
@@ -713,6 +801,66 @@ func (m *ExecResponse) GetExitCode() int32 {
 	return 0
 }
 
+func (m *ExecResponse) GetDeadlineExceeded() bool {
+	if m != nil {
+		return m.DeadlineExceeded
+	}
+	return false
+}
+
+func (m *ExecResponse) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+type ExecStreamingResponse struct {
+	Output               []byte        `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Result               *ExecResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ExecStreamingResponse) Reset()         { *m = ExecStreamingResponse{} }
+func (m *ExecStreamingResponse) String() string { return proto.CompactTextString(m) }
+func (*ExecStreamingResponse) ProtoMessage()    {}
+func (*ExecStreamingResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{17}
+}
+func (m *ExecStreamingResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecStreamingResponse.Unmarshal(m, b)
+}
+func (m *ExecStreamingResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecStreamingResponse.Marshal(b, m, deterministic)
+}
+func (dst *ExecStreamingResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecStreamingResponse.Merge(dst, src)
+}
+func (m *ExecStreamingResponse) XXX_Size() int {
+	return xxx_messageInfo_ExecStreamingResponse.Size(m)
+}
+func (m *ExecStreamingResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecStreamingResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecStreamingResponse proto.InternalMessageInfo
+
+func (m *ExecStreamingResponse) GetOutput() []byte {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (m *ExecStreamingResponse) GetResult() *ExecResponse {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
 type ProcessState struct {
 	Pid                  int32                `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
 	ExitCode             int32                `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
@@ -727,7 +875,7 @@ func (m *ProcessState) Reset()         { *m = ProcessState{} }
 func (m *ProcessState) String() string { return proto.CompactTextString(m) }
 func (*ProcessState) ProtoMessage()    {}
 func (*ProcessState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_executor_1eb9aa6040002cd3, []int{16}
+	return fileDescriptor_executor_dc1f3b1e8e3615b3, []int{18}
 }
 func (m *ProcessState) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ProcessState.Unmarshal(m, b)
@@ -791,7 +939,9 @@ func init() {
 	proto.RegisterType((*SignalRequest)(nil), "hashicorp.nomad.plugins.executor.proto.SignalRequest")
 	proto.RegisterType((*SignalResponse)(nil), "hashicorp.nomad.plugins.executor.proto.SignalResponse")
 	proto.RegisterType((*ExecRequest)(nil), "hashicorp.nomad.plugins.executor.proto.ExecRequest")
+	proto.RegisterType((*ExecLimits)(nil), "hashicorp.nomad.plugins.executor.proto.ExecLimits")
 	proto.RegisterType((*ExecResponse)(nil), "hashicorp.nomad.plugins.executor.proto.ExecResponse")
+	proto.RegisterType((*ExecStreamingResponse)(nil), "hashicorp.nomad.plugins.executor.proto.ExecStreamingResponse")
 	proto.RegisterType((*ProcessState)(nil), "hashicorp.nomad.plugins.executor.proto.ProcessState")
 }
 
@@ -815,6 +965,7 @@ type ExecutorClient interface {
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (Executor_StatsClient, error)
 	Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error)
 	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error)
+	ExecStreaming(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (Executor_ExecStreamingClient, error)
 }
 
 type executorClient struct {
@@ -920,6 +1071,38 @@ func (c *executorClient) Exec(ctx context.Context, in *ExecRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *executorClient) ExecStreaming(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (Executor_ExecStreamingClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Executor_serviceDesc.Streams[1], "/hashicorp.nomad.plugins.executor.proto.Executor/ExecStreaming", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &executorExecStreamingClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Executor_ExecStreamingClient interface {
+	Recv() (*ExecStreamingResponse, error)
+	grpc.ClientStream
+}
+
+type executorExecStreamingClient struct {
+	grpc.ClientStream
+}
+
+func (x *executorExecStreamingClient) Recv() (*ExecStreamingResponse, error) {
+	m := new(ExecStreamingResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ExecutorServer is the server API for Executor service.
 type ExecutorServer interface {
 	Launch(context.Context, *LaunchRequest) (*LaunchResponse, error)
@@ -930,6 +1113,7 @@ type ExecutorServer interface {
 	Stats(*StatsRequest, Executor_StatsServer) error
 	Signal(context.Context, *SignalRequest) (*SignalResponse, error)
 	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+	ExecStreaming(*ExecRequest, Executor_ExecStreamingServer) error
 }
 
 func RegisterExecutorServer(s *grpc.Server, srv ExecutorServer) {
@@ -1083,6 +1267,27 @@ func _Executor_Exec_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Executor_ExecStreaming_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExecutorServer).ExecStreaming(m, &executorExecStreamingServer{stream})
+}
+
+type Executor_ExecStreamingServer interface {
+	Send(*ExecStreamingResponse) error
+	grpc.ServerStream
+}
+
+type executorExecStreamingServer struct {
+	grpc.ServerStream
+}
+
+func (x *executorExecStreamingServer) Send(m *ExecStreamingResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Executor_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hashicorp.nomad.plugins.executor.proto.Executor",
 	HandlerType: (*ExecutorServer)(nil),
@@ -1122,70 +1327,87 @@ var _Executor_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Executor_Stats_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExecStreaming",
+			Handler:       _Executor_ExecStreaming_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "drivers/shared/executor/proto/executor.proto",
 }
 
 func init() {
-	proto.RegisterFile("drivers/shared/executor/proto/executor.proto", fileDescriptor_executor_1eb9aa6040002cd3)
-}
-
-var fileDescriptor_executor_1eb9aa6040002cd3 = []byte{
-	// 885 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4b, 0x6f, 0xe4, 0x44,
-	0x10, 0x5e, 0xc7, 0x99, 0x57, 0xcd, 0xe4, 0xa1, 0x16, 0x0a, 0x5e, 0x73, 0xd8, 0xc1, 0x07, 0x76,
-	0x04, 0x8b, 0x27, 0xca, 0xbe, 0xb8, 0x00, 0x12, 0xc9, 0xc2, 0x25, 0xac, 0x22, 0x67, 0x61, 0x25,
-	0x0e, 0x0c, 0x1d, 0xbb, 0xb1, 0x5b, 0x99, 0x71, 0x9b, 0xee, 0xf6, 0x30, 0x48, 0x48, 0x9c, 0xf8,
-	0x07, 0xfc, 0x52, 0x8e, 0x9c, 0x50, 0xbf, 0x9c, 0x99, 0xec, 0x12, 0x79, 0x40, 0x9c, 0xa6, 0xab,
-	0x5c, 0xdf, 0x57, 0x55, 0xdd, 0x55, 0xdf, 0xc0, 0xa3, 0x8c, 0xd3, 0x25, 0xe1, 0x62, 0x2a, 0x0a,
-	0xcc, 0x49, 0x36, 0x25, 0x2b, 0x92, 0xd6, 0x92, 0xf1, 0x69, 0xc5, 0x99, 0x64, 0x8d, 0x19, 0x6b,
-	0x13, 0x7d, 0x50, 0x60, 0x51, 0xd0, 0x94, 0xf1, 0x2a, 0x2e, 0xd9, 0x02, 0x67, 0x71, 0x35, 0xaf,
-	0x73, 0x5a, 0x8a, 0x78, 0x33, 0x2e, 0x7c, 0x90, 0x33, 0x96, 0xcf, 0x89, 0x21, 0xb9, 0xaa, 0x7f,
-	0x9c, 0x4a, 0xba, 0x20, 0x42, 0xe2, 0x45, 0x65, 0x03, 0x3e, 0xcd, 0xa9, 0x2c, 0xea, 0xab, 0x38,
-	0x65, 0x8b, 0x69, 0xc3, 0x39, 0xd5, 0x9c, 0x53, 0xcb, 0x39, 0x75, 0x95, 0x99, 0x4a, 0x8c, 0x65,
-	0xe0, 0xd1, 0x9f, 0x3e, 0xec, 0x9d, 0xe3, 0xba, 0x4c, 0x8b, 0x84, 0xfc, 0x54, 0x13, 0x21, 0xd1,
-	0x21, 0xf8, 0xe9, 0x22, 0x0b, 0xbc, 0xb1, 0x37, 0x19, 0x24, 0xea, 0x88, 0x10, 0xec, 0x62, 0x9e,
-	0x8b, 0x60, 0x67, 0xec, 0x4f, 0x06, 0x89, 0x3e, 0xa3, 0x97, 0x30, 0xe0, 0x44, 0xb0, 0x9a, 0xa7,
-	0x44, 0x04, 0xfe, 0xd8, 0x9b, 0x0c, 0x4f, 0x8e, 0xe3, 0x7f, 0xea, 0xc9, 0xe6, 0x37, 0x29, 0xe3,
-	0xc4, 0xe1, 0x92, 0x1b, 0x0a, 0xf4, 0x00, 0x86, 0x42, 0x66, 0xac, 0x96, 0xb3, 0x0a, 0xcb, 0x22,
-	0xd8, 0xd5, 0xd9, 0xc1, 0xb8, 0x2e, 0xb0, 0x2c, 0x6c, 0x00, 0xe1, 0xdc, 0x04, 0x74, 0x9a, 0x00,
-	0xc2, 0xb9, 0x0e, 0x38, 0x04, 0x9f, 0x94, 0xcb, 0xa0, 0xab, 0x8b, 0x54, 0x47, 0x55, 0x77, 0x2d,
-	0x08, 0x0f, 0x7a, 0x3a, 0x56, 0x9f, 0xd1, 0x7d, 0xe8, 0x4b, 0x2c, 0xae, 0x67, 0x19, 0xe5, 0x41,
-	0x5f, 0xfb, 0x7b, 0xca, 0x3e, 0xa3, 0x1c, 0x3d, 0x84, 0x03, 0x57, 0xcf, 0x6c, 0x4e, 0x17, 0x54,
-	0x8a, 0x60, 0x30, 0xf6, 0x26, 0xfd, 0x64, 0xdf, 0xb9, 0xcf, 0xb5, 0x17, 0x1d, 0xc3, 0x3b, 0x57,
-	0x58, 0xd0, 0x74, 0x56, 0x71, 0x96, 0x12, 0x21, 0x66, 0x69, 0xce, 0x59, 0x5d, 0x05, 0xa0, 0xa3,
-	0x91, 0xfe, 0x76, 0x61, 0x3e, 0x9d, 0xea, 0x2f, 0xe8, 0x0c, 0xba, 0x0b, 0x56, 0x97, 0x52, 0x04,
-	0xc3, 0xb1, 0x3f, 0x19, 0x9e, 0x3c, 0x6a, 0x79, 0x55, 0x5f, 0x2b, 0x50, 0x62, 0xb1, 0xe8, 0x2b,
-	0xe8, 0x65, 0x64, 0x49, 0xd5, 0x8d, 0x8f, 0x34, 0xcd, 0xc7, 0x2d, 0x69, 0xce, 0x34, 0x2a, 0x71,
-	0xe8, 0xe8, 0x07, 0xd8, 0x77, 0x6f, 0x2e, 0x2a, 0x56, 0x0a, 0x82, 0x5e, 0x42, 0xcf, 0x36, 0xa3,
-	0x1f, 0x7e, 0x78, 0xf2, 0x24, 0x6e, 0x37, 0xa0, 0xb1, 0x6d, 0xf4, 0x52, 0x62, 0x49, 0x12, 0x47,
-	0x12, 0xed, 0xc1, 0xf0, 0x35, 0xa6, 0xd2, 0xce, 0x54, 0xf4, 0x3d, 0x8c, 0x8c, 0xf9, 0x3f, 0xa5,
-	0x3b, 0x87, 0x83, 0xcb, 0xa2, 0x96, 0x19, 0xfb, 0xb9, 0x74, 0x63, 0x7c, 0x04, 0x5d, 0x41, 0xf3,
-	0x12, 0xcf, 0xed, 0x24, 0x5b, 0x0b, 0xbd, 0x0f, 0xa3, 0x9c, 0xe3, 0x94, 0xcc, 0x2a, 0xc2, 0x29,
-	0xcb, 0x82, 0x9d, 0xb1, 0x37, 0xf1, 0x93, 0xa1, 0xf6, 0x5d, 0x68, 0x57, 0x84, 0xe0, 0xf0, 0x86,
-	0xcd, 0x54, 0x1c, 0x15, 0x70, 0xf4, 0x4d, 0x95, 0xa9, 0xa4, 0xcd, 0xf4, 0xda, 0x44, 0x1b, 0x9b,
-	0xe0, 0xfd, 0xe7, 0x4d, 0x88, 0xee, 0xc3, 0xbb, 0x6f, 0x64, 0xb2, 0x45, 0x1c, 0xc2, 0xfe, 0xb7,
-	0x84, 0x0b, 0xca, 0x5c, 0x97, 0xd1, 0x47, 0x70, 0xd0, 0x78, 0xec, 0xdd, 0x06, 0xd0, 0x5b, 0x1a,
-	0x97, 0xed, 0xdc, 0x99, 0xd1, 0x87, 0x30, 0x52, 0xf7, 0xd6, 0x54, 0x1e, 0x42, 0x9f, 0x96, 0x92,
-	0xf0, 0xa5, 0xbd, 0x24, 0x3f, 0x69, 0xec, 0xe8, 0x35, 0xec, 0xd9, 0x58, 0x4b, 0xfb, 0x25, 0x74,
-	0x84, 0x72, 0x6c, 0xd9, 0xe2, 0x2b, 0x2c, 0xae, 0x0d, 0x91, 0x81, 0x47, 0x0f, 0x61, 0xef, 0x52,
-	0xbf, 0xc4, 0xdb, 0x1f, 0xaa, 0xe3, 0x1e, 0x4a, 0x35, 0xeb, 0x02, 0x6d, 0xfb, 0xd7, 0x30, 0x7c,
-	0xb1, 0x22, 0xa9, 0x03, 0x3e, 0x83, 0x7e, 0x46, 0x70, 0x36, 0xa7, 0x25, 0xb1, 0x45, 0x85, 0xb1,
-	0x51, 0xcb, 0xd8, 0xa9, 0x65, 0xfc, 0xca, 0xa9, 0x65, 0xd2, 0xc4, 0x3a, 0x81, 0xdb, 0x79, 0x53,
-	0xe0, 0xfc, 0x1b, 0x81, 0x8b, 0x4e, 0x61, 0x64, 0x92, 0xd9, 0xfe, 0x8f, 0xa0, 0xcb, 0x6a, 0x59,
-	0xd5, 0x52, 0xe7, 0x1a, 0x25, 0xd6, 0x42, 0xef, 0xc1, 0x80, 0xac, 0xa8, 0x9c, 0xa5, 0x2c, 0x23,
-	0x9a, 0xb3, 0x93, 0xf4, 0x95, 0xe3, 0x94, 0x65, 0x24, 0xfa, 0xdd, 0x83, 0xd1, 0xfa, 0xc4, 0xaa,
-	0xdc, 0x15, 0xcd, 0x6c, 0xa7, 0xea, 0x78, 0x27, 0x7e, 0xed, 0x6e, 0xfc, 0xf5, 0xbb, 0x41, 0x31,
-	0xec, 0xaa, 0xff, 0x01, 0x2d, 0x93, 0x77, 0xb7, 0xad, 0xe3, 0x4e, 0xfe, 0xea, 0x41, 0xff, 0x85,
-	0x5d, 0x24, 0xf4, 0x0b, 0x74, 0xcd, 0xf6, 0xa3, 0xa7, 0x6d, 0xb7, 0x6e, 0xe3, 0x1f, 0x22, 0x7c,
-	0xb6, 0x2d, 0xcc, 0xbe, 0xdf, 0x3d, 0x24, 0x60, 0x57, 0xe9, 0x00, 0x7a, 0xdc, 0x96, 0x61, 0x4d,
-	0x44, 0xc2, 0x27, 0xdb, 0x81, 0x9a, 0xa4, 0xbf, 0x41, 0xdf, 0xad, 0x33, 0x7a, 0xde, 0x96, 0xe3,
-	0x96, 0x9c, 0x84, 0x9f, 0x6c, 0x0f, 0x6c, 0x0a, 0xf8, 0xc3, 0x83, 0x83, 0x5b, 0x2b, 0x8d, 0x3e,
-	0x6b, 0xcb, 0xf7, 0x76, 0xd5, 0x09, 0x3f, 0xff, 0xd7, 0xf8, 0xa6, 0xac, 0x5f, 0xa1, 0x67, 0xb5,
-	0x03, 0xb5, 0x7e, 0xd1, 0x4d, 0xf9, 0x09, 0x9f, 0x6f, 0x8d, 0x6b, 0xb2, 0xaf, 0xa0, 0xa3, 0x75,
-	0x01, 0xb5, 0x7e, 0xd6, 0x75, 0xed, 0x0a, 0x9f, 0x6e, 0x89, 0x72, 0x79, 0x8f, 0x3d, 0x35, 0xff,
-	0x46, 0x58, 0xda, 0xcf, 0xff, 0x86, 0x62, 0xb5, 0x9f, 0xff, 0x5b, 0xfa, 0xa5, 0xe7, 0x5f, 0xad,
-	0x61, 0xfb, 0xf9, 0x5f, 0xd3, 0xbb, 0xf6, 0xf3, 0xbf, 0xae, 0x5b, 0xd1, 0xbd, 0x2f, 0x7a, 0xdf,
-	0x75, 0x8c, 0x30, 0x74, 0xf5, 0xcf, 0xe3, 0xbf, 0x03, 0x00, 0x00, 0xff, 0xff, 0x2f, 0x08, 0xfe,
-	0x1f, 0xaa, 0x0a, 0x00, 0x00,
+	proto.RegisterFile("drivers/shared/executor/proto/executor.proto", fileDescriptor_executor_dc1f3b1e8e3615b3)
+}
+
+var fileDescriptor_executor_dc1f3b1e8e3615b3 = []byte{
+	// 1073 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x5b, 0x6f, 0xdc, 0x44,
+	0x14, 0xee, 0xc6, 0x7b, 0xf1, 0x9e, 0xdd, 0x5c, 0x18, 0x41, 0x70, 0x8d, 0x50, 0x17, 0x3f, 0xd0,
+	0x15, 0x2d, 0xde, 0x28, 0x6d, 0x53, 0x5e, 0x0a, 0x12, 0x4d, 0x28, 0x42, 0x69, 0x89, 0x9c, 0x42,
+	0x25, 0x1e, 0x30, 0x8e, 0x7d, 0xd8, 0x1d, 0x65, 0x7d, 0x61, 0x66, 0x1c, 0x52, 0x09, 0xc4, 0x0b,
+	0x08, 0x7e, 0x00, 0x8f, 0xfc, 0x4a, 0x7e, 0x01, 0x9a, 0x8b, 0x9d, 0xdd, 0xa6, 0x50, 0x6f, 0x50,
+	0x9f, 0x3c, 0xe7, 0xf8, 0x9c, 0xef, 0x5c, 0xe6, 0xcc, 0x77, 0xe0, 0x76, 0xc2, 0xe8, 0x19, 0x32,
+	0x3e, 0xe1, 0xb3, 0x88, 0x61, 0x32, 0xc1, 0x73, 0x8c, 0x4b, 0x91, 0xb3, 0x49, 0xc1, 0x72, 0x91,
+	0xd7, 0xa2, 0xaf, 0x44, 0xf2, 0xfe, 0x2c, 0xe2, 0x33, 0x1a, 0xe7, 0xac, 0xf0, 0xb3, 0x3c, 0x8d,
+	0x12, 0xbf, 0x98, 0x97, 0x53, 0x9a, 0x71, 0x7f, 0xd9, 0xce, 0xbd, 0x31, 0xcd, 0xf3, 0xe9, 0x1c,
+	0x35, 0xc8, 0x49, 0xf9, 0xfd, 0x44, 0xd0, 0x14, 0xb9, 0x88, 0xd2, 0xc2, 0x18, 0x3c, 0x98, 0x52,
+	0x31, 0x2b, 0x4f, 0xfc, 0x38, 0x4f, 0x27, 0x35, 0xe6, 0x44, 0x61, 0x4e, 0x0c, 0xe6, 0xa4, 0xca,
+	0x4c, 0x67, 0xa2, 0x25, 0xed, 0xee, 0xfd, 0x6d, 0xc1, 0xfa, 0x61, 0x54, 0x66, 0xf1, 0x2c, 0xc0,
+	0x1f, 0x4a, 0xe4, 0x82, 0x6c, 0x81, 0x15, 0xa7, 0x89, 0xd3, 0x1a, 0xb5, 0xc6, 0xfd, 0x40, 0x1e,
+	0x09, 0x81, 0x76, 0xc4, 0xa6, 0xdc, 0x59, 0x1b, 0x59, 0xe3, 0x7e, 0xa0, 0xce, 0xe4, 0x09, 0xf4,
+	0x19, 0xf2, 0xbc, 0x64, 0x31, 0x72, 0xc7, 0x1a, 0xb5, 0xc6, 0x83, 0xdd, 0x1d, 0xff, 0xdf, 0x6a,
+	0x32, 0xf1, 0x75, 0x48, 0x3f, 0xa8, 0xfc, 0x82, 0x0b, 0x08, 0x72, 0x03, 0x06, 0x5c, 0x24, 0x79,
+	0x29, 0xc2, 0x22, 0x12, 0x33, 0xa7, 0xad, 0xa2, 0x83, 0x56, 0x1d, 0x45, 0x62, 0x66, 0x0c, 0x90,
+	0x31, 0x6d, 0xd0, 0xa9, 0x0d, 0x90, 0x31, 0x65, 0xb0, 0x05, 0x16, 0x66, 0x67, 0x4e, 0x57, 0x25,
+	0x29, 0x8f, 0x32, 0xef, 0x92, 0x23, 0x73, 0x7a, 0xca, 0x56, 0x9d, 0xc9, 0x75, 0xb0, 0x45, 0xc4,
+	0x4f, 0xc3, 0x84, 0x32, 0xc7, 0x56, 0xfa, 0x9e, 0x94, 0xf7, 0x29, 0x23, 0x37, 0x61, 0xb3, 0xca,
+	0x27, 0x9c, 0xd3, 0x94, 0x0a, 0xee, 0xf4, 0x47, 0xad, 0xb1, 0x1d, 0x6c, 0x54, 0xea, 0x43, 0xa5,
+	0x25, 0x3b, 0xf0, 0xe6, 0x49, 0xc4, 0x69, 0x1c, 0x16, 0x2c, 0x8f, 0x91, 0xf3, 0x30, 0x9e, 0xb2,
+	0xbc, 0x2c, 0x1c, 0x50, 0xd6, 0x44, 0xfd, 0x3b, 0xd2, 0xbf, 0x1e, 0xaa, 0x3f, 0x64, 0x1f, 0xba,
+	0x69, 0x5e, 0x66, 0x82, 0x3b, 0x83, 0x91, 0x35, 0x1e, 0xec, 0xde, 0x6e, 0xd8, 0xaa, 0xc7, 0xd2,
+	0x29, 0x30, 0xbe, 0xe4, 0x11, 0xf4, 0x12, 0x3c, 0xa3, 0xb2, 0xe3, 0x43, 0x05, 0xf3, 0x61, 0x43,
+	0x98, 0x7d, 0xe5, 0x15, 0x54, 0xde, 0xde, 0x77, 0xb0, 0x51, 0xdd, 0x39, 0x2f, 0xf2, 0x8c, 0x23,
+	0x79, 0x02, 0x3d, 0x53, 0x8c, 0xba, 0xf8, 0xc1, 0xee, 0x5d, 0xbf, 0xd9, 0x80, 0xfa, 0xa6, 0xd0,
+	0x63, 0x11, 0x09, 0x0c, 0x2a, 0x10, 0x6f, 0x1d, 0x06, 0xcf, 0x22, 0x2a, 0xcc, 0x4c, 0x79, 0xdf,
+	0xc2, 0x50, 0x8b, 0xaf, 0x29, 0xdc, 0x21, 0x6c, 0x1e, 0xcf, 0x4a, 0x91, 0xe4, 0x3f, 0x66, 0xd5,
+	0x18, 0x6f, 0x43, 0x97, 0xd3, 0x69, 0x16, 0xcd, 0xcd, 0x24, 0x1b, 0x89, 0xbc, 0x07, 0xc3, 0x29,
+	0x8b, 0x62, 0x0c, 0x0b, 0x64, 0x34, 0x4f, 0x9c, 0xb5, 0x51, 0x6b, 0x6c, 0x05, 0x03, 0xa5, 0x3b,
+	0x52, 0x2a, 0x8f, 0xc0, 0xd6, 0x05, 0x9a, 0xce, 0xd8, 0x9b, 0xc1, 0xf6, 0x57, 0x45, 0x22, 0x83,
+	0xd6, 0xd3, 0x6b, 0x02, 0x2d, 0xbd, 0x84, 0xd6, 0xff, 0x7e, 0x09, 0xde, 0x75, 0x78, 0xfb, 0x52,
+	0x24, 0x93, 0xc4, 0x16, 0x6c, 0x7c, 0x8d, 0x8c, 0xd3, 0xbc, 0xaa, 0xd2, 0xbb, 0x05, 0x9b, 0xb5,
+	0xc6, 0xf4, 0xd6, 0x81, 0xde, 0x99, 0x56, 0x99, 0xca, 0x2b, 0xd1, 0xfb, 0x00, 0x86, 0xb2, 0x6f,
+	0x75, 0xe6, 0x2e, 0xd8, 0x34, 0x13, 0xc8, 0xce, 0x4c, 0x93, 0xac, 0xa0, 0x96, 0xbd, 0x67, 0xb0,
+	0x6e, 0x6c, 0x0d, 0xec, 0x67, 0xd0, 0xe1, 0x52, 0xb1, 0x62, 0x89, 0x4f, 0x23, 0x7e, 0xaa, 0x81,
+	0xb4, 0xbb, 0x77, 0x13, 0xd6, 0x8f, 0xd5, 0x4d, 0xbc, 0xfc, 0xa2, 0x3a, 0xd5, 0x45, 0xc9, 0x62,
+	0x2b, 0x43, 0x53, 0xfe, 0x5f, 0x6b, 0x30, 0x38, 0x38, 0xc7, 0xb8, 0xf2, 0xdc, 0x03, 0x3b, 0xc1,
+	0x28, 0x99, 0xd3, 0x0c, 0x4d, 0x56, 0xae, 0xaf, 0xe9, 0xd2, 0xaf, 0xe8, 0xd2, 0x7f, 0x5a, 0xd1,
+	0x65, 0x50, 0xdb, 0x56, 0x0c, 0xb7, 0x76, 0x99, 0xe1, 0xac, 0x05, 0x86, 0x7b, 0x17, 0xe0, 0x94,
+	0xce, 0xe7, 0xa1, 0x9a, 0x0c, 0x45, 0x48, 0x56, 0xd0, 0x97, 0x9a, 0x47, 0x52, 0x41, 0xbe, 0x80,
+	0xae, 0x21, 0x89, 0x8e, 0x0a, 0xbd, 0xdb, 0x74, 0x82, 0x65, 0x05, 0x9a, 0x48, 0x02, 0x83, 0x20,
+	0x99, 0x87, 0x63, 0x11, 0xb1, 0x48, 0x60, 0xa8, 0x19, 0xcd, 0xe9, 0x6a, 0xe6, 0xa9, 0xd4, 0xc7,
+	0x4a, 0x4b, 0xde, 0x81, 0xfe, 0x29, 0x62, 0x11, 0xce, 0x30, 0x4a, 0x14, 0xad, 0xd9, 0x81, 0x2d,
+	0x15, 0x9f, 0x63, 0x94, 0x78, 0x5f, 0x02, 0x5c, 0x60, 0xcb, 0x92, 0x32, 0x1a, 0xa3, 0x69, 0xaa,
+	0x3a, 0xab, 0xc2, 0x8b, 0xd2, 0x8c, 0xbc, 0x3c, 0x4a, 0xc0, 0x14, 0xd3, 0x9c, 0x3d, 0x0f, 0xd3,
+	0x13, 0x45, 0xe3, 0x9d, 0xc0, 0xd6, 0x8a, 0xc7, 0x27, 0xde, 0x1f, 0x2d, 0x18, 0xea, 0x7e, 0x9b,
+	0x19, 0xd8, 0x86, 0x6e, 0x5e, 0x8a, 0xa2, 0x14, 0x0a, 0x75, 0x18, 0x18, 0x49, 0xa2, 0xe0, 0x39,
+	0x15, 0x61, 0x9c, 0x27, 0xa8, 0xd0, 0x3b, 0x81, 0x2d, 0x15, 0x0f, 0xf3, 0x04, 0xc9, 0x2d, 0x78,
+	0xa3, 0xea, 0x7c, 0x88, 0xe7, 0x31, 0x62, 0x82, 0x89, 0x0a, 0x65, 0x07, 0x5b, 0xd5, 0x8f, 0x03,
+	0xa3, 0x57, 0xc3, 0xa0, 0x1b, 0xd0, 0xd6, 0x11, 0xb4, 0xe4, 0xfd, 0x0c, 0x6f, 0xc9, 0x4c, 0x8e,
+	0x05, 0xc3, 0x28, 0xa5, 0xd9, 0xf4, 0x95, 0x29, 0x1d, 0x42, 0x97, 0x21, 0x2f, 0xe7, 0xc2, 0x59,
+	0x5b, 0x8d, 0x60, 0x16, 0x0b, 0x0e, 0x0c, 0x86, 0xf7, 0x5b, 0x0b, 0x86, 0x8b, 0xcc, 0x23, 0x3b,
+	0x59, 0xd0, 0xc4, 0x34, 0x57, 0x1e, 0xff, 0xbb, 0x07, 0x17, 0x33, 0x6e, 0x2d, 0xce, 0x38, 0xf1,
+	0xa1, 0x2d, 0xf7, 0xb9, 0xd3, 0x7e, 0xe5, 0xf4, 0x2a, 0xbb, 0xdd, 0x5f, 0xfb, 0x60, 0x1f, 0x98,
+	0x7c, 0xc9, 0x73, 0xe8, 0x6a, 0x16, 0x27, 0xf7, 0x9a, 0x16, 0xb7, 0xb4, 0xe9, 0xdd, 0xbd, 0x55,
+	0xdd, 0xcc, 0x3b, 0xbc, 0x46, 0x38, 0xb4, 0x25, 0x9f, 0x93, 0x3b, 0x4d, 0x11, 0x16, 0x96, 0x81,
+	0x7b, 0x77, 0x35, 0xa7, 0x3a, 0xe8, 0x2f, 0x60, 0x57, 0xb4, 0x4c, 0xee, 0x37, 0xc5, 0x78, 0x61,
+	0x2d, 0xb8, 0x1f, 0xad, 0xee, 0x58, 0x27, 0xf0, 0x67, 0x0b, 0x36, 0x5f, 0xa0, 0x66, 0xf2, 0x71,
+	0x53, 0xbc, 0x97, 0x6f, 0x0f, 0xf7, 0x93, 0x2b, 0xfb, 0xd7, 0x69, 0xfd, 0x04, 0x3d, 0xb3, 0x03,
+	0x48, 0xe3, 0x1b, 0x5d, 0x5e, 0x23, 0xee, 0xfd, 0x95, 0xfd, 0xea, 0xe8, 0xe7, 0xd0, 0x51, 0xfc,
+	0x4e, 0x1a, 0x5f, 0xeb, 0xe2, 0x0e, 0x72, 0xef, 0xad, 0xe8, 0x55, 0xc5, 0xdd, 0x69, 0xc9, 0xf9,
+	0xd7, 0x0b, 0xa2, 0xf9, 0xfc, 0x2f, 0x6d, 0x1e, 0x77, 0x6f, 0x55, 0xb7, 0xc5, 0xf9, 0x97, 0xcf,
+	0xb0, 0xf9, 0xfc, 0x2f, 0xac, 0x2d, 0xf7, 0x4a, 0x54, 0xe4, 0x5d, 0x23, 0xbf, 0xb7, 0x60, 0x7d,
+	0x89, 0x04, 0xaf, 0x16, 0xfe, 0xc1, 0x2a, 0x4e, 0x97, 0x08, 0x57, 0x76, 0xfe, 0xd3, 0xde, 0x37,
+	0x1d, 0x4d, 0x51, 0x5d, 0xf5, 0xb9, 0xf3, 0xcf, 0x00, 0xf4, 0x4a, 0x8a, 0x48, 0xfc, 0x0c, 0x00,
+	0x00,
 }
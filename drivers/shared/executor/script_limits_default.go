@@ -0,0 +1,14 @@
+// +build !linux
+
+package executor
+
+import "os/exec"
+
+// scriptLimitsSupported is true if ScriptLimits can be applied on this
+// platform.
+const scriptLimitsSupported = false
+
+// limitScriptCommand is a no-op on platforms that don't support ScriptLimits.
+func limitScriptCommand(cmd *exec.Cmd, limits *ScriptLimits) error {
+	return nil
+}
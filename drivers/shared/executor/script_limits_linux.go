@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	lconfigs "github.com/opencontainers/runc/libcontainer/configs"
+	"golang.org/x/sys/unix"
+)
+
+// scriptLimitsSupported is true if ScriptLimits can be applied on this
+// platform.
+const scriptLimitsSupported = true
+
+// scriptLimitsShim is the subcommand that applies ScriptLimits to itself
+// before execve into a script.
+const scriptLimitsShim = "script-limits-shim"
+
+// init is only run on linux and is used when a script is run within
+// ScriptLimits. The shim takes over the process, applying the limits before
+// execve into the script so the script never runs without them.
+//
+// Like the libcontainer shim, this subcommand handler is implemented as an
+// `init` so it's handled anywhere this package is used, including tests.
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == scriptLimitsShim {
+		// The scheduling priority is per thread, so set it on the thread
+		// that will execve
+		runtime.LockOSThread()
+		if err := runScriptLimitsShim(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to apply script limits: %v\n", err)
+			os.Exit(1)
+		}
+		panic("--this line should have never been executed, congratulations--")
+	}
+}
+
+// limitScriptCommand rewrites cmd to be run by the script limits shim, which
+// applies limits before execve into the command.
+func limitScriptCommand(cmd *exec.Cmd, limits *ScriptLimits) error {
+	shim, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the script limits shim: %v", err)
+	}
+
+	args := []string{
+		shim,
+		scriptLimitsShim,
+		strconv.Itoa(limits.Nice),
+		strconv.FormatInt(int64(limits.CPU), 10),
+		strconv.Itoa(limits.MemoryMB),
+		cmd.Path,
+	}
+	cmd.Args = append(args, cmd.Args...)
+	cmd.Path = shim
+	return nil
+}
+
+// runScriptLimitsShim applies the limits passed by limitScriptCommand and
+// execs the script. It only returns on error.
+func runScriptLimitsShim(args []string) error {
+	if len(args) < 5 {
+		return fmt.Errorf("expected limits and a command but received %q", args)
+	}
+	nice, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid nice %q: %v", args[0], err)
+	}
+	cpu, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid CPU limit %q: %v", args[1], err)
+	}
+	memoryMB, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid memory limit %q: %v", args[2], err)
+	}
+	limits := &ScriptLimits{
+		Nice:     nice,
+		CPU:      time.Duration(cpu),
+		MemoryMB: memoryMB,
+	}
+
+	if limits.Nice != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, limits.Nice); err != nil {
+			return fmt.Errorf("failed to set priority: %v", err)
+		}
+	}
+	for _, rlimit := range scriptRlimits(limits) {
+		rlim := &unix.Rlimit{Cur: rlimit.Soft, Max: rlimit.Hard}
+		if err := unix.Setrlimit(rlimit.Type, rlim); err != nil {
+			return fmt.Errorf("failed to set resource limit %d: %v", rlimit.Type, err)
+		}
+	}
+
+	return syscall.Exec(args[3], args[4:], os.Environ())
+}
+
+// scriptRlimits returns the resource limits to apply for limits.
+func scriptRlimits(limits *ScriptLimits) []lconfigs.Rlimit {
+	var rlimits []lconfigs.Rlimit
+	if limits.CPU > 0 {
+		seconds := uint64(math.Ceil(limits.CPU.Seconds()))
+		rlimits = append(rlimits, lconfigs.Rlimit{Type: unix.RLIMIT_CPU, Hard: seconds, Soft: seconds})
+	}
+	if limits.MemoryMB > 0 {
+		bytes := uint64(limits.MemoryMB) * 1024 * 1024
+		rlimits = append(rlimits, lconfigs.Rlimit{Type: unix.RLIMIT_AS, Hard: bytes, Soft: bytes})
+	}
+	return rlimits
+}
+
+// withPriority runs start, which starts a process, from a thread with the
+// given scheduling priority so the process inherits it from the moment it's
+// forked. The thread is never unlocked so it exits rather than running other
+// goroutines at that priority.
+func withPriority(nice int, start func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, nice); err != nil {
+			errCh <- fmt.Errorf("failed to set priority: %v", err)
+			return
+		}
+		errCh <- start()
+	}()
+	return <-errCh
+}
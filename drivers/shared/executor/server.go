@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"sync"
 	"syscall"
 	"time"
 
@@ -139,18 +140,65 @@ func (s *grpcExecutorServer) Signal(ctx context.Context, req *proto.SignalReques
 }
 
 func (s *grpcExecutorServer) Exec(ctx context.Context, req *proto.ExecRequest) (*proto.ExecResponse, error) {
+	return s.exec(req, nil)
+}
+
+func (s *grpcExecutorServer) ExecStreaming(req *proto.ExecRequest, stream proto.Executor_ExecStreamingServer) error {
+	// Output may be written from more than one goroutine and must not be
+	// sent once the result has been
+	var lock sync.Mutex
+	done := false
+	output := func(p []byte) {
+		lock.Lock()
+		defer lock.Unlock()
+		if done {
+			return
+		}
+		if err := stream.Send(&proto.ExecStreamingResponse{Output: p}); err != nil {
+			done = true
+		}
+	}
+
+	resp, err := s.exec(req, output)
+
+	lock.Lock()
+	defer lock.Unlock()
+	done = true
+	if err != nil {
+		return err
+	}
+	return stream.Send(&proto.ExecStreamingResponse{Result: resp})
+}
+
+// exec runs the command in req, streaming its output to output if not nil.
+func (s *grpcExecutorServer) exec(req *proto.ExecRequest, output func([]byte)) (*proto.ExecResponse, error) {
 	deadline, err := ptypes.Timestamp(req.Deadline)
 	if err != nil {
 		return nil, err
 	}
 
-	out, exit, err := s.impl.Exec(deadline, req.Cmd, req.Args)
-	if err != nil {
+	opts := &ExecOptions{
+		KillGrace:      time.Duration(req.KillGrace),
+		Output:         output,
+		SeparateStderr: req.SeparateStderr,
+		KeepHead:       req.KeepHead,
+	}
+	if limits := req.Limits; limits != nil {
+		opts.Limits = &ScriptLimits{
+			Nice:     int(limits.Nice),
+			CPU:      time.Duration(limits.Cpu),
+			MemoryMB: int(limits.MemoryMb),
+		}
+	}
+	res, err := s.impl.ExecWithOptions(deadline, req.Cmd, req.Args, opts)
+	if err != nil && (err != context.DeadlineExceeded || res == nil) {
 		return nil, err
 	}
 
 	return &proto.ExecResponse{
-		Output:   out,
-		ExitCode: int32(exit),
+		Output:           res.Output,
+		Stderr:           res.Stderr,
+		ExitCode:         int32(res.ExitCode),
+		DeadlineExceeded: err == context.DeadlineExceeded,
 	}, nil
 }
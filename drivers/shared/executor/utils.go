@@ -1,9 +1,11 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	hclog "github.com/hashicorp/go-hclog"
@@ -11,6 +13,7 @@ import (
 	"github.com/hashicorp/nomad/drivers/shared/executor/proto"
 	"github.com/hashicorp/nomad/helper/discover"
 	"github.com/hashicorp/nomad/plugins/base"
+	"github.com/hashicorp/nomad/plugins/drivers"
 )
 
 const (
@@ -111,6 +114,41 @@ func newExecutorClient(config *plugin.ClientConfig, logger hclog.Logger) (Execut
 	return executorPlugin, executorClient, nil
 }
 
+// ExecTask runs the command in opts with the executor for drivers
+// implementing ExecTaskWithOptions.
+func ExecTask(e Executor, opts *drivers.ExecOptions) (*drivers.ExecTaskResult, error) {
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("error cmd must have at least one value")
+	}
+
+	execOpts := &ExecOptions{
+		KillGrace:      opts.KillGrace,
+		Output:         opts.Output,
+		SeparateStderr: opts.SeparateStderr,
+		KeepHead:       opts.KeepHead,
+	}
+	if opts.Nice != 0 || opts.CPULimit != 0 || opts.MemoryLimitMB != 0 {
+		execOpts.Limits = &ScriptLimits{
+			Nice:     opts.Nice,
+			CPU:      opts.CPULimit,
+			MemoryMB: opts.MemoryLimitMB,
+		}
+	}
+	res, err := e.ExecWithOptions(time.Now().Add(opts.Timeout), opts.Command[0], opts.Command[1:], execOpts)
+	if err != nil && (err != context.DeadlineExceeded || res == nil) {
+		return nil, err
+	}
+
+	return &drivers.ExecTaskResult{
+		Stdout: res.Output,
+		Stderr: res.Stderr,
+		ExitResult: &drivers.ExitResult{
+			ExitCode: res.ExitCode,
+			Err:      err,
+		},
+	}, nil
+}
+
 func processStateToProto(ps *ProcessState) (*proto.ProcessState, error) {
 	timestamp, err := ptypes.TimestampProto(ps.Time)
 	if err != nil {
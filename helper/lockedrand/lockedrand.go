@@ -0,0 +1,33 @@
+package lockedrand
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Rand is a source of randomness that is safe for concurrent use.
+type Rand struct {
+	r *rand.Rand
+	l sync.Mutex
+}
+
+// New returns a Rand backed by src. If src is nil a source seeded with the
+// current time is used.
+func New(src rand.Source) *Rand {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+	return &Rand{r: rand.New(src)}
+}
+
+// Stagger returns a random duration in [0, d). Zero is returned if d is not
+// positive.
+func (l *Rand) Stagger(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	l.l.Lock()
+	defer l.l.Unlock()
+	return time.Duration(l.r.Int63n(int64(d)))
+}
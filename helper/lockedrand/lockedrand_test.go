@@ -0,0 +1,32 @@
+package lockedrand
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestStagger(t *testing.T) {
+	r := New(nil)
+	for _, d := range []time.Duration{0, -time.Second} {
+		if s := r.Stagger(d); s != 0 {
+			t.Fatalf("expected no stagger for %v; got %v", d, s)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if s := r.Stagger(time.Second); s < 0 || s >= time.Second {
+			t.Fatalf("stagger %v outside of [0, 1s)", s)
+		}
+	}
+}
+
+func TestStagger_Source(t *testing.T) {
+	r1 := New(rand.NewSource(1))
+	r2 := New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		if s1, s2 := r1.Stagger(time.Hour), r2.Stagger(time.Hour); s1 != s2 {
+			t.Fatalf("expected the same source to stagger the same; got %v and %v", s1, s2)
+		}
+	}
+}
@@ -1276,6 +1276,21 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 			"address_mode",
 			"grpc_service",
 			"grpc_use_tls",
+			"stream_interval",
+			"default_output",
+			"dedupe",
+			"kill_grace",
+			"truncate_from",
+			"webhook",
+			"webhook_interval",
+			"status_names",
+			"align_to_clock",
+			"nice",
+			"rlimit_cpu",
+			"rlimit_memory",
+			"base64_output",
+			"start_order",
+			"separate_stderr",
 		}
 		if err := helper.CheckHCLKeys(co.Val, valid); err != nil {
 			return multierror.Prefix(err, "check ->")
@@ -1317,6 +1332,30 @@ func parseChecks(service *api.Service, checkObjs *ast.ObjectList) error {
 			delete(cm, "header")
 		}
 
+		// Merge repeated 'status_names' stanzas into a single
+		// map[string]string.
+		if namesI, ok := cm["status_names"]; ok {
+			namesRaw, ok := namesI.([]map[string]interface{})
+			if !ok {
+				return fmt.Errorf("check -> status_names -> expected a []map[string]string but found %T", namesI)
+			}
+			m := map[string]string{}
+			for _, rawm := range namesRaw {
+				for k, vI := range rawm {
+					v, ok := vI.(string)
+					if !ok {
+						return fmt.Errorf("check -> status_names -> %q expected a string but found %T", k, vI)
+					}
+					m[k] = v
+				}
+			}
+
+			check.StatusNames = m
+
+			// Remove "status_names" as it has been parsed
+			delete(cm, "status_names")
+		}
+
 		delete(cm, "check_restart")
 
 		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
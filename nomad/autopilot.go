@@ -89,7 +89,24 @@ func (d *AutopilotDelegate) PromoteNonVoters(conf *autopilot.Config, health auto
 		return nil, fmt.Errorf("failed to get raft configuration: %v", err)
 	}
 
-	return autopilot.PromoteStableServers(conf, health, future.Configuration().Servers), nil
+	// Don't promote servers tagged as non-voters, such as those demoted by
+	// DemoteVoter
+	nonVoters := make(map[raft.ServerID]struct{})
+	for _, member := range d.server.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if valid && parts.NonVoter {
+			nonVoters[raft.ServerID(parts.ID)] = struct{}{}
+			nonVoters[raft.ServerID(parts.Addr.String())] = struct{}{}
+		}
+	}
+
+	var promotions []raft.Server
+	for _, server := range autopilot.PromoteStableServers(conf, health, future.Configuration().Servers) {
+		if _, ok := nonVoters[server.ID]; !ok {
+			promotions = append(promotions, server)
+		}
+	}
+	return promotions, nil
 }
 
 func (d *AutopilotDelegate) Raft() *raft.Raft {
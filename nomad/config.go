@@ -3,14 +3,19 @@ package nomad
 import (
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	log "github.com/hashicorp/go-hclog"
+	multierror "github.com/hashicorp/go-multierror"
+	version "github.com/hashicorp/go-version"
 
 	"github.com/hashicorp/memberlist"
+	"github.com/hashicorp/nomad/command/agent/consul"
 	"github.com/hashicorp/nomad/helper/pluginutils/loader"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -60,6 +65,11 @@ type Config struct {
 	// must be handled via `atomic.*Int32()` calls.
 	BootstrapExpect int32
 
+	// RegionBootstrapExpect overrides BootstrapExpect for servers of the
+	// given regions so a single configuration can be shared by regions
+	// with different numbers of servers.
+	RegionBootstrapExpect map[string]int
+
 	// DataDir is the directory to store our state in
 	DataDir string
 
@@ -104,6 +114,53 @@ type Config struct {
 	// RaftTimeout is applied to any network traffic for raft. Defaults to 10s.
 	RaftTimeout time.Duration
 
+	// VerifyRaftOnStart verifies the integrity of the Raft log and snapshot
+	// stores before opening them on startup, so a corrupt store fails with
+	// a descriptive error instead of confusing failures later.
+	VerifyRaftOnStart bool
+
+	// StaleReadOnQuorumLoss controls reads that aren't explicitly stale when
+	// no leader can be found, such as when quorum is lost or the leader stepped
+	// down because it lost contact with quorum. If true they are served from
+	// local state and the response's KnownLeader is false. Otherwise they fail
+	// with ErrNoQuorum.
+	//
+	// It doesn't change when a leader steps down: Raft steps down a leader
+	// that hasn't heard from a quorum within RaftConfig.LeaderLeaseTimeout
+	// either way, and reads it serves until then are within its lease. Once
+	// it has stepped down it's handled like any other server without a leader.
+	StaleReadOnQuorumLoss bool
+
+	// LeaderElectionDelay is how long a freshly started server waits before
+	// it may campaign for leadership, giving its peers time to rejoin after
+	// a restart. The delay does not apply when this server bootstraps the
+	// cluster. Zero disables the delay.
+	LeaderElectionDelay time.Duration
+
+	// MinLeadershipInterval is how long this server holds leadership before
+	// it voluntarily gives it up, such as by demoting itself to a non-voter
+	// or leaving the cluster when its region is evacuated. It dampens
+	// leadership flapping caused by automated tooling. Leaving while shutting
+	// down and losing leadership are never delayed. Zero disables the minimum.
+	MinLeadershipInterval time.Duration
+
+	// BroadcastLeadership makes this server announce itself to the other
+	// regions with a serf user event when it becomes the leader of its
+	// region, so they learn the new leader without waiting to infer it.
+	BroadcastLeadership bool
+
+	// LeaderBroadcastInterval is how often the leader repeats its
+	// announcement while BroadcastLeadership is enabled, so regions that
+	// missed an event or joined later still learn the leader.
+	LeaderBroadcastInterval time.Duration
+
+	// RandSource is the source of randomness used wherever randomness drives
+	// timing, such as heartbeat TTL and RPC jitter or the delay of follow-up
+	// evaluations. A fixed source makes these decisions reproducible in
+	// tests. Defaults to a source seeded with the current time. Serf and
+	// Raft use their own randomness and are unaffected.
+	RandSource rand.Source
+
 	// (Enterprise-only) NonVoter is used to prevent this server from being added
 	// as a voting member of the Raft cluster.
 	NonVoter bool
@@ -118,6 +175,35 @@ type Config struct {
 	// SerfConfig is the configuration for the serf cluster
 	SerfConfig *serf.Config
 
+	// SerfEventBuffer is the number of serf events that may be queued
+	// waiting to be handled. User events arriving while the queue is full
+	// are dropped and counted rather than letting the backlog grow, while
+	// member events and queries wait for room and are counted as delayed.
+	SerfEventBuffer int
+
+	// TombstoneTimeout is how long serf retains the tombstones of servers
+	// that left or were reaped before purging them from its member state.
+	// Shorter timeouts bound the state kept in high-churn clusters, but a
+	// purged server that rejoins is treated as new. Zero uses serf's default.
+	TombstoneTimeout time.Duration
+
+	// SerfSnapshotPath is where serf persists known members so a restarted
+	// server can rejoin them without waiting on gossip. It overrides the
+	// default snapshot in the DataDir and is honored in dev mode. If empty
+	// the default is used outside of dev mode.
+	SerfSnapshotPath string
+
+	// UnknownTagHandler is called during reconciliation with the serf tags of
+	// a server that this version of Nomad doesn't recognize, such as those
+	// advertised by newer servers. It may be nil.
+	UnknownTagHandler func(member serf.Member, unknownTags map[string]string)
+
+	// MinServerVersion is the minimum Nomad version of servers added to the
+	// Raft cluster. Servers advertising an older build aren't added by the
+	// leader so upgrades happen in a safe order, while those already in the
+	// cluster are left in place. If empty any version is allowed.
+	MinServerVersion string
+
 	// Node name is the name we use to advertise. Defaults to hostname.
 	NodeName string
 
@@ -154,6 +240,16 @@ type Config struct {
 	// leader election.
 	ReconcileInterval time.Duration
 
+	// MembershipCheckInterval is how often serf and Raft membership are
+	// compared to detect servers that are only in one of them.
+	MembershipCheckInterval time.Duration
+
+	// MembershipDiscrepancyWindow is how long a server may be in only one of
+	// serf and Raft before the discrepancy is reported and logged. Shorter
+	// disagreements are expected while membership is being reconciled. Zero
+	// disables the comparison.
+	MembershipDiscrepancyWindow time.Duration
+
 	// EvalGCInterval is how often we dispatch a job to GC evaluations
 	EvalGCInterval time.Duration
 
@@ -242,6 +338,10 @@ type Config struct {
 	// ConsulConfig is this Agent's Consul configuration
 	ConsulConfig *config.ConsulConfig
 
+	// ConsulHealth is used to query the health of services' checks across
+	// the nodes registered in Consul. Service health queries fail if nil.
+	ConsulHealth consul.HealthAPI
+
 	// VaultConfig is this Agent's Vault configuration
 	VaultConfig *config.VaultConfig
 
@@ -321,6 +421,80 @@ func (c *Config) CheckVersion() error {
 	return nil
 }
 
+// Validate checks the invariants of the configuration that a server relies on
+// and returns an error listing every problem found.
+func (c *Config) Validate() error {
+	var mErr multierror.Error
+	if err := c.CheckVersion(); err != nil {
+		multierror.Append(&mErr, err)
+	}
+
+	if c.BootstrapExpect < 0 {
+		multierror.Append(&mErr, fmt.Errorf("BootstrapExpect must not be negative: %d", c.BootstrapExpect))
+	}
+	for region, expect := range c.RegionBootstrapExpect {
+		if expect < 0 {
+			multierror.Append(&mErr, fmt.Errorf("BootstrapExpect for region %q must not be negative: %d", region, expect))
+		}
+	}
+	if c.Bootstrap && c.BootstrapExpect > 1 {
+		multierror.Append(&mErr, fmt.Errorf("Bootstrap can not be combined with a BootstrapExpect of %d", c.BootstrapExpect))
+	}
+	if c.NonVoter && c.Bootstrap {
+		multierror.Append(&mErr, fmt.Errorf("NonVoter servers can not bootstrap the cluster"))
+	}
+
+	if c.MinServerVersion != "" {
+		minVersion, err := version.NewVersion(c.MinServerVersion)
+		if err != nil {
+			multierror.Append(&mErr, fmt.Errorf("MinServerVersion %q is invalid: %v", c.MinServerVersion, err))
+		} else if build, err := version.NewVersion(c.Build); err == nil && !meetsMinimumVersion(build, minVersion) {
+			multierror.Append(&mErr, fmt.Errorf("Build %q is below MinServerVersion %q", c.Build, c.MinServerVersion))
+		}
+	}
+
+	if c.MinLeadershipInterval < 0 {
+		multierror.Append(&mErr, fmt.Errorf("MinLeadershipInterval must not be negative: %v", c.MinLeadershipInterval))
+	}
+
+	if c.MembershipDiscrepancyWindow < 0 {
+		multierror.Append(&mErr, fmt.Errorf("MembershipDiscrepancyWindow must not be negative: %v", c.MembershipDiscrepancyWindow))
+	} else if c.MembershipDiscrepancyWindow > 0 && c.MembershipCheckInterval <= 0 {
+		multierror.Append(&mErr, fmt.Errorf("MembershipCheckInterval must be positive: %v", c.MembershipCheckInterval))
+	}
+
+	if c.TombstoneTimeout < 0 {
+		multierror.Append(&mErr, fmt.Errorf("TombstoneTimeout must not be negative: %v", c.TombstoneTimeout))
+	}
+
+	if c.SerfEventBuffer <= 0 {
+		multierror.Append(&mErr, fmt.Errorf("SerfEventBuffer must be positive: %d", c.SerfEventBuffer))
+	}
+
+	if c.Region == "" {
+		multierror.Append(&mErr, fmt.Errorf("Region must be set"))
+	} else if strings.ContainsAny(c.Region, " \t\n") {
+		multierror.Append(&mErr, fmt.Errorf("Region %q must not contain whitespace", c.Region))
+	}
+	if c.Datacenter == "" {
+		multierror.Append(&mErr, fmt.Errorf("Datacenter must be set"))
+	} else if strings.ContainsAny(c.Datacenter, " \t\n") {
+		multierror.Append(&mErr, fmt.Errorf("Datacenter %q must not contain whitespace", c.Datacenter))
+	}
+
+	if c.RPCAddr == nil {
+		multierror.Append(&mErr, fmt.Errorf("RPCAddr must be set"))
+	} else if c.SerfConfig != nil && c.SerfConfig.MemberlistConfig != nil {
+		// Port zero picks a free port so it never conflicts
+		serfPort := c.SerfConfig.MemberlistConfig.BindPort
+		if c.RPCAddr.Port != 0 && c.RPCAddr.Port == serfPort {
+			multierror.Append(&mErr, fmt.Errorf("RPC and Serf can not both use port %d", serfPort))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	hostname, err := os.Hostname()
@@ -340,8 +514,12 @@ func DefaultConfig() *Config {
 		LogOutput:                        os.Stderr,
 		RPCAddr:                          DefaultRPCAddr,
 		SerfConfig:                       serf.DefaultConfig(),
+		SerfEventBuffer:                  256,
 		NumSchedulers:                    1,
 		ReconcileInterval:                60 * time.Second,
+		MembershipCheckInterval:          30 * time.Second,
+		MembershipDiscrepancyWindow:      5 * time.Minute,
+		LeaderBroadcastInterval:          30 * time.Second,
 		EvalGCInterval:                   5 * time.Minute,
 		EvalGCThreshold:                  1 * time.Hour,
 		JobGCInterval:                    5 * time.Minute,
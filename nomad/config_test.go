@@ -0,0 +1,96 @@
+package nomad
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c := DefaultConfig()
+	require.NoError(c.Validate())
+
+	c.BootstrapExpect = -1
+	err := c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "BootstrapExpect must not be negative: -1")
+
+	c.BootstrapExpect = 0
+	c.RegionBootstrapExpect = map[string]int{"region2": -2}
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), `BootstrapExpect for region "region2" must not be negative: -2`)
+	c.RegionBootstrapExpect = nil
+
+	c.MinLeadershipInterval = -time.Second
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "MinLeadershipInterval must not be negative: -1s")
+	c.MinLeadershipInterval = 0
+
+	c.TombstoneTimeout = -time.Minute
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "TombstoneTimeout must not be negative: -1m0s")
+	c.TombstoneTimeout = 0
+
+	c.MembershipCheckInterval = 0
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "MembershipCheckInterval must be positive: 0s")
+	c.MembershipDiscrepancyWindow = 0
+	require.NoError(c.Validate())
+	c.BootstrapExpect = -1
+
+	// All problems are reported at once
+	c.Region = ""
+	c.RPCAddr = &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4648}
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "BootstrapExpect must not be negative")
+	require.Contains(err.Error(), "Region must be set")
+	require.Contains(err.Error(), "RPC and Serf can not both use port 4648")
+}
+
+func TestConfig_Validate_NonVoter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// Non-voters may wait for the expected voters to bootstrap
+	c := DefaultConfig()
+	c.NonVoter = true
+	c.BootstrapExpect = 3
+	require.NoError(c.Validate())
+
+	c.BootstrapExpect = 0
+	c.Bootstrap = true
+	err := c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), "NonVoter servers can not bootstrap the cluster")
+}
+
+func TestConfig_Validate_MinServerVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	c := DefaultConfig()
+	c.Build = "0.9.2"
+	c.MinServerVersion = "0.9.0"
+	require.NoError(c.Validate())
+
+	c.MinServerVersion = "not-a-version"
+	err := c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), `MinServerVersion "not-a-version" is invalid`)
+
+	// A server can't require a newer version than its own
+	c.MinServerVersion = "0.10.0"
+	err = c.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), `Build "0.9.2" is below MinServerVersion "0.10.0"`)
+}
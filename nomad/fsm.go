@@ -5,6 +5,7 @@ import (
 	"io"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	metrics "github.com/armon/go-metrics"
@@ -86,6 +87,10 @@ type nomadFSM struct {
 	// new state store). Everything internal here is synchronized by the
 	// Raft side, so doesn't need to lock this.
 	stateLock sync.RWMutex
+
+	// applying is 1 while a log is being applied; otherwise 0. Accessed
+	// with atomics.
+	applying int32
 }
 
 // nomadSnapshot is used to provide a snapshot of the current
@@ -171,6 +176,9 @@ func (n *nomadFSM) TimeTable() *TimeTable {
 }
 
 func (n *nomadFSM) Apply(log *raft.Log) interface{} {
+	atomic.StoreInt32(&n.applying, 1)
+	defer atomic.StoreInt32(&n.applying, 0)
+
 	buf := log.Data
 	msgType := structs.MessageType(buf[0])
 
@@ -102,7 +102,7 @@ func (h *nodeHeartbeater) resetHeartbeatTimer(id string) (time.Duration, error)
 	// Compute the target TTL value
 	n := len(h.heartbeatTimers)
 	ttl := lib.RateScaledInterval(h.config.MaxHeartbeatsPerSecond, h.config.MinHeartbeatTTL, n)
-	ttl += lib.RandomStagger(ttl)
+	ttl += h.rng.Stagger(ttl)
 
 	// Reset the TTL
 	h.resetHeartbeatTimerLocked(id, ttl+h.config.HeartbeatGrace)
@@ -2,6 +2,7 @@ package nomad
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -61,6 +62,42 @@ func TestHeartbeat_ResetHeartbeatTimer(t *testing.T) {
 	}
 }
 
+func TestHeartbeat_ResetHeartbeatTimer_RandSource(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	// ttls returns the TTLs of several heartbeats reset by a server using a
+	// fixed source of randomness
+	ttls := func() []time.Duration {
+		s := TestServer(t, func(c *Config) {
+			c.RandSource = rand.NewSource(42)
+		})
+		defer s.Shutdown()
+
+		// Wait without issuing RPCs since forwarding them may use the
+		// source to jitter retries
+		testutil.WaitForResult(func() (bool, error) {
+			return s.IsLeader(), fmt.Errorf("not leader")
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+
+		var out []time.Duration
+		for i := 0; i < 5; i++ {
+			ttl, err := s.resetHeartbeatTimer(fmt.Sprintf("node-%d", i))
+			require.NoError(err)
+			out = append(out, ttl)
+		}
+		return out
+	}
+
+	first := ttls()
+	require.Equal(first, ttls())
+
+	// The TTLs are staggered rather than all being the same
+	require.NotEqual(first[0], first[1])
+}
+
 func TestHeartbeat_ResetHeartbeatTimer_Nonleader(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"math/rand"
 	"net"
 	"sync"
 	"time"
@@ -68,6 +67,7 @@ func (s *Server) monitorLeadership() {
 				}
 
 				weAreLeaderCh = make(chan struct{})
+				s.setLeadershipTerm(weAreLeaderCh)
 				leaderLoop.Add(1)
 				go func(ch chan struct{}) {
 					defer leaderLoop.Done()
@@ -82,6 +82,7 @@ func (s *Server) monitorLeadership() {
 				}
 
 				s.logger.Debug("shutting down leader loop")
+				s.setLeadershipTerm(nil)
 				close(weAreLeaderCh)
 				leaderLoop.Wait()
 				weAreLeaderCh = nil
@@ -94,6 +95,53 @@ func (s *Server) monitorLeadership() {
 	}
 }
 
+// setLeadershipTerm records that we acquired leadership now, with stopCh
+// closed when it's lost, or that we lost it if stopCh is nil.
+func (s *Server) setLeadershipTerm(stopCh chan struct{}) {
+	s.leaderSinceLock.Lock()
+	defer s.leaderSinceLock.Unlock()
+	s.leaderStopCh = stopCh
+	if stopCh != nil {
+		s.leaderSince = time.Now()
+	} else {
+		s.leaderSince = time.Time{}
+	}
+}
+
+// waitMinLeadershipInterval delays voluntarily giving up leadership until
+// MinLeadershipInterval has elapsed since we acquired it. It returns an error
+// if we lose leadership or the server shuts down while waiting.
+func (s *Server) waitMinLeadershipInterval() error {
+	if s.config.MinLeadershipInterval <= 0 {
+		return nil
+	}
+
+	s.leaderSinceLock.Lock()
+	since, stopCh := s.leaderSince, s.leaderStopCh
+	s.leaderSinceLock.Unlock()
+	if stopCh == nil {
+		return raft.ErrNotLeader
+	}
+
+	remaining := s.config.MinLeadershipInterval - time.Since(since)
+	if remaining <= 0 {
+		return nil
+	}
+
+	s.logger.Info("deferring leadership change until minimum leadership interval elapses",
+		"leader_since", since, "remaining", remaining)
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-stopCh:
+		return raft.ErrNotLeader
+	case <-s.shutdownCh:
+		return fmt.Errorf("server shutting down")
+	}
+}
+
 // leaderLoop runs as long as we are the leader to run various
 // maintenance activities
 func (s *Server) leaderLoop(stopCh chan struct{}) {
@@ -287,6 +335,14 @@ func (s *Server) establishLeadership(stopCh chan struct{}) error {
 		return err
 	}
 
+	// Announce the new leader to the other regions
+	if s.config.BroadcastLeadership {
+		go s.broadcastLeadershipPeriodically(stopCh)
+	}
+
+	// Start the registered leader tasks
+	s.startLeaderTasks()
+
 	return nil
 }
 
@@ -529,7 +585,7 @@ func (s *Server) reapFailedEvaluations(stopCh chan struct{}) {
 			// scheduling for the job after the cluster is hopefully more stable
 			// due to the fairly large backoff.
 			followupEvalWait := s.config.EvalFailedFollowupBaselineDelay +
-				time.Duration(rand.Int63n(int64(s.config.EvalFailedFollowupDelayRange)))
+				s.rng.Stagger(s.config.EvalFailedFollowupDelayRange)
 			followupEval := eval.CreateFailedFollowUpEval(followupEvalWait)
 			updateEval.NextEval = followupEval.ID
 
@@ -708,6 +764,9 @@ func (s *Server) iterateJobSummaryMetrics(summary *structs.JobSummary) {
 func (s *Server) revokeLeadership() error {
 	defer metrics.MeasureSince([]string{"nomad", "leader", "revoke_leadership"}, time.Now())
 
+	// Stop the leader tasks first so they don't run past our leadership
+	s.stopLeaderTasks()
+
 	// Clear the leader token since we are no longer the leader.
 	s.setLeaderAcl("")
 
@@ -773,7 +832,18 @@ func (s *Server) reconcile() error {
 func (s *Server) reconcileMember(member serf.Member) error {
 	// Check if this is a member we should handle
 	valid, parts := isNomadServer(member)
-	if !valid || parts.Region != s.config.Region {
+	if !valid {
+		return nil
+	}
+
+	// Let the operator know about tags from newer servers
+	if handler := s.config.UnknownTagHandler; handler != nil {
+		if unknown := unknownServerTags(member); unknown != nil {
+			handler(member, unknown)
+		}
+	}
+
+	if parts.Region != s.config.Region {
 		return nil
 	}
 	defer metrics.MeasureSince([]string{"nomad", "leader", "reconcileMember"}, time.Now())
@@ -781,7 +851,14 @@ func (s *Server) reconcileMember(member serf.Member) error {
 	var err error
 	switch member.Status {
 	case serf.StatusAlive:
-		err = s.addRaftPeer(member, parts)
+		// Servers below the minimum version are kept out of raft, but ones
+		// that are already peers aren't removed since that could cost quorum
+		if s.belowMinServerVersion(parts) {
+			s.logger.Warn("not adding server below minimum version to raft", "server", parts.Name,
+				"version", parts.Build.String(), "min_version", s.config.MinServerVersion)
+		} else {
+			err = s.addRaftPeer(member, parts)
+		}
 	case serf.StatusLeft, StatusReap:
 		err = s.removeRaftPeer(member, parts)
 	}
@@ -792,6 +869,20 @@ func (s *Server) reconcileMember(member serf.Member) error {
 	return nil
 }
 
+// belowMinServerVersion returns true if the server's build is below the
+// configured MinServerVersion.
+func (s *Server) belowMinServerVersion(parts *serverParts) bool {
+	if s.config.MinServerVersion == "" {
+		return false
+	}
+	minVersion, err := version.NewVersion(s.config.MinServerVersion)
+	if err != nil {
+		// Validated when the server was created
+		return false
+	}
+	return !meetsMinimumVersion(&parts.Build, minVersion)
+}
+
 // reconcileJobSummaries reconciles the summaries of all the jobs registered in
 // the system
 // COMPAT 0.4 -> 0.4.1
@@ -1283,3 +1374,87 @@ func (s *Server) getOrCreateSchedulerConfig() *structs.SchedulerConfiguration {
 
 	return config
 }
+
+// leaderTask is a task run periodically while this server is the leader.
+type leaderTask struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context)
+}
+
+// RegisterLeaderTask registers fn to be run every interval while this server
+// is the leader. The context passed to fn is cancelled as soon as leadership
+// is lost. Tasks registered while this server is the leader start
+// immediately.
+func (s *Server) RegisterLeaderTask(name string, interval time.Duration, fn func(ctx context.Context)) {
+	if interval <= 0 {
+		s.logger.Error("ignoring leader task with non-positive interval", "task", name, "interval", interval)
+		return
+	}
+
+	task := &leaderTask{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+	}
+
+	s.leaderTasksLock.Lock()
+	defer s.leaderTasksLock.Unlock()
+	s.leaderTasks = append(s.leaderTasks, task)
+	if s.leaderTasksCtx != nil {
+		go s.runLeaderTask(s.leaderTasksCtx, task)
+	}
+}
+
+// startLeaderTasks starts running the registered leader tasks.
+func (s *Server) startLeaderTasks() {
+	s.leaderTasksLock.Lock()
+	defer s.leaderTasksLock.Unlock()
+	if s.leaderTasksCancel != nil {
+		s.leaderTasksCancel()
+	}
+	s.leaderTasksCtx, s.leaderTasksCancel = context.WithCancel(context.Background())
+	for _, task := range s.leaderTasks {
+		go s.runLeaderTask(s.leaderTasksCtx, task)
+	}
+}
+
+// stopLeaderTasks cancels the running leader tasks.
+func (s *Server) stopLeaderTasks() {
+	s.leaderTasksLock.Lock()
+	defer s.leaderTasksLock.Unlock()
+	if s.leaderTasksCancel != nil {
+		s.leaderTasksCancel()
+	}
+	s.leaderTasksCtx, s.leaderTasksCancel = nil, nil
+}
+
+// runLeaderTask runs the task immediately and then every interval until ctx is
+// cancelled or the server shuts down.
+func (s *Server) runLeaderTask(ctx context.Context, task *leaderTask) {
+	s.logger.Debug("starting leader task", "task", task.name)
+	ticker := time.NewTicker(task.interval)
+	defer ticker.Stop()
+	for {
+		// A tick may be ready along with the cancellation, so check before
+		// every run rather than relying on the select below
+		select {
+		case <-ctx.Done():
+			s.logger.Debug("stopped leader task", "task", task.name)
+			return
+		case <-s.shutdownCh:
+			return
+		default:
+		}
+
+		task.fn(ctx)
+		select {
+		case <-ctx.Done():
+			s.logger.Debug("stopped leader task", "task", task.name)
+			return
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,14 +1,20 @@
 package nomad
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/consul/testutil/retry"
 	memdb "github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -79,6 +85,75 @@ func TestLeader_LeftServer(t *testing.T) {
 	})
 }
 
+func TestLeader_LeaderElectionDelay(t *testing.T) {
+	// The delay doesn't apply to the server bootstrapping the cluster
+	s1 := TestServer(t, func(c *Config) {
+		c.LeaderElectionDelay = time.Hour
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	// Delay the remaining servers long enough that only opening the gate
+	// lets them campaign
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+		c.LeaderElectionDelay = time.Hour
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+		c.LeaderElectionDelay = time.Hour
+	})
+	defer s3.Shutdown()
+	TestJoin(t, s1, s2, s3)
+
+	for _, s := range []*Server{s1, s2, s3} {
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s.numPeers()
+			return peers == 3, nil
+		}, func(err error) {
+			t.Fatalf("should have 3 peers")
+		})
+	}
+
+	// Kill the leader while the remaining servers are still delayed
+	s1.Shutdown()
+	testutil.WaitForResult(func() (bool, error) {
+		for _, s := range []*Server{s2, s3} {
+			if s.raft.State() != raft.Candidate {
+				return false, fmt.Errorf("%s is %v", s.config.NodeName, s.raft.State())
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("should have started campaigning: %v", err)
+	})
+
+	// Campaigning doesn't win an election while the gate is closed
+	for i := 0; i < 20; i++ {
+		require.False(t, s2.IsLeader(), "s2 became leader before the delay elapsed")
+		require.False(t, s3.IsLeader(), "s3 became leader before the delay elapsed")
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Once the delay elapses the server can win an election
+	s2.electionDelay.Open()
+	testutil.WaitForResult(func() (bool, error) {
+		return s2.IsLeader(), nil
+	}, func(err error) {
+		t.Fatalf("should have elected s2 after the delay")
+	})
+	require.False(t, s3.IsLeader())
+}
+
 func TestLeader_LeftLeader(t *testing.T) {
 	s1 := TestServer(t, nil)
 	defer s1.Shutdown()
@@ -1126,6 +1201,43 @@ func TestLeader_RevokeLeadership_MultipleTimes(t *testing.T) {
 	require.Nil(t, s1.revokeLeadership())
 }
 
+func TestLeader_UnknownTagHandler(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var lock sync.Mutex
+	var got []map[string]string
+	s1 := TestServer(t, func(c *Config) {
+		c.UnknownTagHandler = func(m serf.Member, unknown map[string]string) {
+			lock.Lock()
+			defer lock.Unlock()
+			got = append(got, unknown)
+		}
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Known tags don't trigger the handler
+	member := s1.LocalMember()
+	require.NoError(s1.reconcileMember(member))
+	lock.Lock()
+	require.Empty(got)
+	lock.Unlock()
+
+	// Inject a member advertising a tag from the future
+	tags := make(map[string]string, len(member.Tags)+1)
+	for k, v := range member.Tags {
+		tags[k] = v
+	}
+	tags["future_feature"] = "1"
+	member.Tags = tags
+	require.NoError(s1.reconcileMember(member))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal([]map[string]string{{"future_feature": "1"}}, got)
+}
+
 // Test doing an inplace upgrade on a server from raft protocol 2 to 3
 // This verifies that removing the server and adding it back with a uuid works
 // even if the server's address stays the same.
@@ -1210,3 +1322,336 @@ func TestServer_ReconcileMember(t *testing.T) {
 		t.Fatalf("got %d server ids want %d", got, want)
 	}
 }
+
+func TestLeader_RegisterLeaderTask(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	s2 := TestServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+
+	for _, s := range []*Server{s1, s2} {
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s.numPeers()
+			return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+	require.True(s1.IsLeader())
+
+	// Register the task on both servers; only the leader runs it
+	var leaderRuns, followerRuns int32
+	cancelledCh := make(chan struct{})
+	var once sync.Once
+	s1.RegisterLeaderTask("test", 10*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&leaderRuns, 1)
+		go func() {
+			<-ctx.Done()
+			once.Do(func() { close(cancelledCh) })
+		}()
+	})
+	s2.RegisterLeaderTask("test", 10*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&followerRuns, 1)
+	})
+
+	testutil.WaitForResult(func() (bool, error) {
+		runs := atomic.LoadInt32(&leaderRuns)
+		return runs >= 3, fmt.Errorf("expected at least 3 runs; got %d", runs)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	require.Zero(atomic.LoadInt32(&followerRuns))
+
+	// Losing quorum makes the leader step down
+	s2.Shutdown()
+	select {
+	case <-cancelledCh:
+	case <-time.After(testutil.Timeout(10 * time.Second)):
+		t.Fatalf("leader task not cancelled after losing leadership")
+	}
+	require.False(s1.IsLeader())
+
+	// The task no longer runs
+	runs := atomic.LoadInt32(&leaderRuns)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(runs, atomic.LoadInt32(&leaderRuns))
+}
+
+// TestLeader_RunLeaderTask_Cancel asserts a leader task cancelled between ticks
+// isn't run again even though the next tick is ready.
+func TestLeader_RunLeaderTask_Cancel(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{
+		logger:     testlog.HCLogger(t),
+		shutdownCh: make(chan struct{}),
+	}
+
+	// The select picks randomly among ready cases, so repeat to make a run
+	// after cancellation likely if it's possible
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		runs := 0
+		task := &leaderTask{
+			name:     "test",
+			interval: time.Millisecond,
+			fn: func(context.Context) {
+				runs++
+
+				// Let the next tick fire before cancelling
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+			},
+		}
+
+		s.runLeaderTask(ctx, task)
+		if runs != 1 {
+			t.Fatalf("expected the task to run once; ran %d times", runs)
+		}
+	}
+}
+
+func TestLeader_MinServerVersion(t *testing.T) {
+	t.Parallel()
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.MinServerVersion = "0.8.0"
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// An older server joins the gossip pool but is kept out of raft
+	s2 := TestServer(t, func(c *Config) {
+		c.Build = "0.7.1"
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	// A server meeting the minimum version is added
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+	TestJoin(t, s1, s2, s3)
+
+	testutil.WaitForResult(func() (bool, error) {
+		if members := len(s1.Members()); members != 3 {
+			return false, fmt.Errorf("expected 3 members; got %d", members)
+		}
+		peers, err := s1.numPeers()
+		if err != nil {
+			return false, err
+		}
+		return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	future := s1.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, server := range future.Configuration().Servers {
+		if server.ID == raft.ServerID(s2.config.NodeID) || server.Address == raft.ServerAddress(s2.config.RPCAddr.String()) {
+			t.Fatalf("server below minimum version was added to raft: %v", server)
+		}
+	}
+}
+
+// testMinLeadershipCluster starts a three server cluster of voters led by the
+// returned server, which is configured with the given MinLeadershipInterval.
+func testMinLeadershipCluster(t *testing.T, dir string, interval time.Duration) (*Server, []*Server) {
+	s1 := TestServer(t, func(c *Config) {
+		c.MinLeadershipInterval = interval
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	testutil.WaitForLeader(t, s1.RPC)
+
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	TestJoin(t, s1, s2, s3)
+
+	// Wait for autopilot to promote the joined servers
+	testutil.WaitForResult(func() (bool, error) {
+		future := s1.raft.GetConfiguration()
+		if err := future.Error(); err != nil {
+			return false, err
+		}
+		voters := 0
+		for _, server := range future.Configuration().Servers {
+			if server.Suffrage == raft.Voter {
+				voters++
+			}
+		}
+		return voters == 3, fmt.Errorf("expected 3 voters; got %d", voters)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	return s1, []*Server{s2, s3}
+}
+
+// TestLeader_MinLeadershipInterval asserts the leader demoting itself, which
+// hands leadership to another voter, is deferred until MinLeadershipInterval
+// has elapsed.
+func TestLeader_MinLeadershipInterval(t *testing.T) {
+	t.Parallel()
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	interval := 5 * time.Second
+	s1, followers := testMinLeadershipCluster(t, dir, interval)
+	defer s1.Shutdown()
+	for _, s := range followers {
+		defer s.Shutdown()
+	}
+
+	s1.leaderSinceLock.Lock()
+	since := s1.leaderSince
+	s1.leaderSinceLock.Unlock()
+	if since.IsZero() {
+		t.Fatalf("leadership acquisition wasn't recorded")
+	}
+	if time.Since(since) >= interval {
+		t.Fatalf("cluster took longer than %v to form", interval)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s1.DemoteVoter(s1.config.NodeID)
+	}()
+	for time.Since(since) < interval-500*time.Millisecond {
+		select {
+		case err := <-errCh:
+			t.Fatalf("leadership given up after %v; expected at least %v: %v", time.Since(since), interval, err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		require.True(t, s1.IsLeader())
+	}
+
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatalf("timed out waiting for demotion")
+	}
+	if elapsed := time.Since(since); elapsed < interval {
+		t.Fatalf("leadership given up after %v; expected at least %v", elapsed, interval)
+	}
+
+	// Leadership moves to one of the remaining voters
+	testutil.WaitForResult(func() (bool, error) {
+		for _, s := range followers {
+			if s.IsLeader() {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("no follower took over leadership")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	require.False(t, s1.IsLeader())
+}
+
+// TestLeader_MinLeadershipInterval_Shutdown asserts leaving as part of
+// shutting down isn't deferred by MinLeadershipInterval.
+func TestLeader_MinLeadershipInterval_Shutdown(t *testing.T) {
+	t.Parallel()
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1, followers := testMinLeadershipCluster(t, dir, time.Hour)
+	defer s1.Shutdown()
+	for _, s := range followers {
+		defer s.Shutdown()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s1.Leave()
+	}()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatalf("leave was deferred by the minimum leadership interval")
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		peers, err := followers[0].numPeers()
+		if err != nil {
+			return false, err
+		}
+		return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+// TestLeader_MinServerVersion_ExistingPeer asserts a server below the minimum
+// version that is already a raft peer isn't removed.
+func TestLeader_MinServerVersion_ExistingPeer(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.MinServerVersion = "0.8.0"
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	s2 := TestServer(t, func(c *Config) {
+		c.Build = "0.7.1"
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	// The older server was a peer before the minimum version was enforced
+	future := s1.raft.AddVoter(raft.ServerID(s2.config.NodeID), raft.ServerAddress(s2.config.RPCAddr.String()), 0, 0)
+	require.NoError(future.Error())
+
+	TestJoin(t, s1, s2)
+	var member serf.Member
+	testutil.WaitForResult(func() (bool, error) {
+		for _, m := range s1.Members() {
+			if m.Name == s2.LocalMember().Name && m.Status == serf.StatusAlive {
+				member = m
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("server below minimum version hasn't joined")
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	require.NoError(s1.reconcileMember(member))
+	peers, err := s1.numPeers()
+	require.NoError(err)
+	require.Equal(2, peers)
+}
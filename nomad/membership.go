@@ -0,0 +1,174 @@
+package nomad
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/serf"
+)
+
+const (
+	// DiscrepancySerfOnly is a server alive in serf but missing from the
+	// Raft configuration.
+	DiscrepancySerfOnly = "serf-only"
+
+	// DiscrepancyRaftOnly is a server in the Raft configuration without an
+	// alive serf member.
+	DiscrepancyRaftOnly = "raft-only"
+)
+
+// Discrepancy is a server of this region whose serf and Raft membership
+// disagree.
+type Discrepancy struct {
+	// ID is the server's node ID, or its Raft ID if it isn't known to serf
+	ID string
+
+	// Name is the server's serf member name. It is empty for servers not
+	// known to serf.
+	Name string
+
+	// Address is the server's RPC address
+	Address string
+
+	// Kind is either DiscrepancySerfOnly or DiscrepancyRaftOnly
+	Kind string
+
+	// Since is when the discrepancy was first detected
+	Since time.Time
+}
+
+// trackedDiscrepancy is a Discrepancy along with whether it has been logged.
+type trackedDiscrepancy struct {
+	Discrepancy
+	logged bool
+}
+
+// MembershipDiscrepancies returns the servers whose serf and Raft membership
+// have disagreed for at least the MembershipDiscrepancyWindow, sorted by ID.
+// Transient disagreements that are still being reconciled aren't returned.
+func (s *Server) MembershipDiscrepancies() []Discrepancy {
+	s.discrepanciesLock.Lock()
+	defer s.discrepanciesLock.Unlock()
+
+	now := time.Now()
+	var persistent []Discrepancy
+	for _, d := range s.discrepancies {
+		if now.Sub(d.Since) >= s.config.MembershipDiscrepancyWindow {
+			persistent = append(persistent, d.Discrepancy)
+		}
+	}
+	sort.Slice(persistent, func(i, j int) bool {
+		if persistent[i].ID != persistent[j].ID {
+			return persistent[i].ID < persistent[j].ID
+		}
+		return persistent[i].Kind < persistent[j].Kind
+	})
+	return persistent
+}
+
+// monitorMembership periodically compares serf and Raft membership until the
+// server shuts down. It is disabled if MembershipDiscrepancyWindow is zero.
+func (s *Server) monitorMembership() {
+	if s.config.MembershipDiscrepancyWindow <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.MembershipCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+			s.checkMembership(time.Now())
+		}
+	}
+}
+
+// checkMembership records the current discrepancies between serf and Raft
+// membership, keeping when each was first detected, and logs those that have
+// persisted beyond the MembershipDiscrepancyWindow.
+func (s *Server) checkMembership(now time.Time) {
+	found, err := s.findDiscrepancies()
+	if err != nil {
+		s.logger.Debug("failed to compare serf and raft membership", "error", err)
+		return
+	}
+
+	s.discrepanciesLock.Lock()
+	defer s.discrepanciesLock.Unlock()
+
+	tracked := make(map[string]*trackedDiscrepancy, len(found))
+	for _, d := range found {
+		key := d.Kind + "/" + d.ID
+		t, ok := s.discrepancies[key]
+		if !ok {
+			t = &trackedDiscrepancy{Discrepancy: d}
+			t.Since = now
+		}
+		tracked[key] = t
+
+		if !t.logged && now.Sub(t.Since) >= s.config.MembershipDiscrepancyWindow {
+			s.logger.Warn("serf and raft membership disagree", "server", t.Name, "id", t.ID,
+				"address", t.Address, "kind", t.Kind, "since", t.Since)
+			t.logged = true
+		}
+	}
+
+	// Log resolved discrepancies that had been reported
+	for key, t := range s.discrepancies {
+		if _, ok := tracked[key]; !ok && t.logged {
+			s.logger.Info("serf and raft membership agree again", "server", t.Name, "id", t.ID, "kind", t.Kind)
+		}
+	}
+	s.discrepancies = tracked
+}
+
+// findDiscrepancies returns the servers of this region that are alive in serf
+// but missing from the Raft configuration, or in the Raft configuration
+// without an alive serf member.
+func (s *Server) findDiscrepancies() ([]Discrepancy, error) {
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, err
+	}
+	servers := future.Configuration().Servers
+
+	var found []Discrepancy
+	matched := make(map[raft.ServerID]struct{}, len(servers))
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid || parts.Region != s.config.Region || parts.Status != serf.StatusAlive {
+			continue
+		}
+
+		addr := parts.Addr.String()
+		inRaft := false
+		for _, server := range servers {
+			if server.ID == raft.ServerID(parts.ID) || server.Address == raft.ServerAddress(addr) {
+				matched[server.ID] = struct{}{}
+				inRaft = true
+			}
+		}
+		if !inRaft {
+			found = append(found, Discrepancy{
+				ID:      parts.ID,
+				Name:    member.Name,
+				Address: addr,
+				Kind:    DiscrepancySerfOnly,
+			})
+		}
+	}
+
+	for _, server := range servers {
+		if _, ok := matched[server.ID]; !ok {
+			found = append(found, Discrepancy{
+				ID:      string(server.ID),
+				Address: string(server.Address),
+				Kind:    DiscrepancyRaftOnly,
+			})
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,63 @@
+package nomad
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_MembershipDiscrepancies(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	window := 500 * time.Millisecond
+	s1 := TestServer(t, func(c *Config) {
+		c.MinServerVersion = "0.8.0"
+		c.MembershipCheckInterval = 50 * time.Millisecond
+		c.MembershipDiscrepancyWindow = window
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// A single server agrees with itself
+	time.Sleep(2 * s1.config.MembershipCheckInterval)
+	require.Empty(s1.MembershipDiscrepancies())
+
+	// An older server joins serf but is kept out of raft
+	s2 := TestServer(t, func(c *Config) {
+		c.Build = "0.7.1"
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+
+	joined := time.Now()
+	testutil.WaitForResult(func() (bool, error) {
+		discrepancies := s1.MembershipDiscrepancies()
+		if len(discrepancies) != 1 {
+			return false, fmt.Errorf("expected 1 discrepancy; got %#v", discrepancies)
+		}
+		d := discrepancies[0]
+		if d.ID != s2.config.NodeID || d.Kind != DiscrepancySerfOnly {
+			return false, fmt.Errorf("unexpected discrepancy %#v", d)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// It is only reported once it persisted beyond the window
+	require.True(time.Since(joined) >= window, "reported after %v", time.Since(joined))
+	d := s1.MembershipDiscrepancies()[0]
+	require.Equal(s2.config.NodeName+".global", d.Name)
+	require.True(time.Since(d.Since) >= window)
+}
@@ -53,6 +53,11 @@ func QuotaPolicy(policy string) string {
 	return fmt.Sprintf("quota {\n\tpolicy = %q\n}\n", policy)
 }
 
+// OperatorPolicy is a helper for generating the hcl for a given operator policy.
+func OperatorPolicy(policy string) string {
+	return fmt.Sprintf("operator {\n\tpolicy = %q\n}\n", policy)
+}
+
 // CreatePolicy creates a policy with the given name and rule.
 func CreatePolicy(t testing.T, state StateStore, index uint64, name, rule string) {
 	t.Helper()
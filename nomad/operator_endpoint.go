@@ -192,6 +192,85 @@ REMOVE:
 	return nil
 }
 
+// EvacuateRegion is used to decommission every server in the region of the
+// request. It is forwarded to the leader of that region.
+func (op *Operator) EvacuateRegion(args *structs.EvacuateRegionRequest, reply *structs.GenericResponse) error {
+	if done, err := op.srv.forward("Operator.EvacuateRegion", args, args, reply); done {
+		return err
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	return op.srv.evacuateRegion(args.AuthToken)
+}
+
+// ServerLeave is used by the leader of a region being evacuated to instruct
+// this server to leave the cluster. It is handled by the server it is sent
+// to rather than forwarded to the leader.
+func (op *Operator) ServerLeave(args *structs.ServerLeaveRequest, reply *structs.GenericResponse) error {
+	if args.Region != op.srv.config.Region {
+		return fmt.Errorf("server is in region %q, not %q", op.srv.config.Region, args.Region)
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	op.logger.Info("leave requested by cluster leader")
+
+	// Leave blocks while waiting to be removed from raft, so reply first
+	go func() {
+		if err := op.srv.leave(true); err != nil {
+			op.logger.Error("failed to leave cluster", "error", err)
+		}
+	}()
+	return nil
+}
+
+// DemoteVoter is used to convert a server from a raft voter to a non-voter. It
+// is forwarded to the leader of the region.
+func (op *Operator) DemoteVoter(args *structs.DemoteVoterRequest, reply *structs.GenericResponse) error {
+	if done, err := op.srv.forward("Operator.DemoteVoter", args, args, reply); done {
+		return err
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	return op.srv.demoteVoter(args.NodeID, args.AuthToken)
+}
+
+// ServerNonVoter is used by the leader to instruct this server to set or
+// remove its non-voter tag while demoting it. It is handled by the server it
+// is sent to rather than forwarded to the leader.
+func (op *Operator) ServerNonVoter(args *structs.ServerNonVoterRequest, reply *structs.GenericResponse) error {
+	if args.Region != op.srv.config.Region {
+		return fmt.Errorf("server is in region %q, not %q", op.srv.config.Region, args.Region)
+	}
+
+	// Check management permissions
+	if aclObj, err := op.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	op.logger.Info("non-voter tag change requested by cluster leader", "non_voter", args.NonVoter)
+	return op.srv.setNonVoterTag(args.NonVoter)
+}
+
 // AutopilotGetConfiguration is used to retrieve the current Autopilot configuration.
 func (op *Operator) AutopilotGetConfiguration(args *structs.GenericRequest, reply *structs.AutopilotConfig) error {
 	if done, err := op.srv.forward("Operator.AutopilotGetConfiguration", args, args, reply); done {
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/serf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -494,3 +495,141 @@ func TestOperator_SchedulerSetConfiguration_ACL(t *testing.T) {
 	}
 
 }
+
+func TestOperator_EvacuateRegion_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+	state := s1.fsm.State()
+
+	// Create ACL token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.EvacuateRegionRequest{}
+	arg.Region = s1.config.Region
+	var reply structs.GenericResponse
+
+	// Try with no token and expect permission denied
+	err := msgpackrpc.CallWithCodec(codec, "Operator.EvacuateRegion", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token and expect permission denied
+	arg.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.EvacuateRegion", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with a management token. The only region can't be evacuated.
+	arg.AuthToken = root.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.EvacuateRegion", &arg, &reply)
+	require.Error(err)
+	require.Contains(err.Error(), "no other region")
+}
+
+func TestOperator_ServerLeave_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+	state := s1.fsm.State()
+
+	// Create ACL token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.ServerLeaveRequest{}
+	arg.Region = s1.config.Region
+	var reply structs.GenericResponse
+
+	// Try with no token and expect permission denied
+	err := msgpackrpc.CallWithCodec(codec, "Operator.ServerLeave", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token and expect permission denied
+	arg.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.ServerLeave", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with a management token and expect the server to leave
+	arg.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.ServerLeave", &arg, &reply))
+	testutil.WaitForResult(func() (bool, error) {
+		if status := s1.serf.LocalMember().Status; status != serf.StatusLeft {
+			return false, fmt.Errorf("expected server to have left serf; got %v", status)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestOperator_DemoteVoter_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+	state := s1.fsm.State()
+
+	// Create ACL token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.DemoteVoterRequest{NodeID: "unknown"}
+	arg.Region = s1.config.Region
+	var reply structs.GenericResponse
+
+	// Try with no token and expect permission denied
+	err := msgpackrpc.CallWithCodec(codec, "Operator.DemoteVoter", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token and expect permission denied
+	arg.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.DemoteVoter", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with a management token and expect the unknown server to be rejected
+	arg.AuthToken = root.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.DemoteVoter", &arg, &reply)
+	require.EqualError(err, `unknown server "unknown"`)
+}
+
+func TestOperator_ServerNonVoter_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	require := require.New(t)
+	state := s1.fsm.State()
+
+	// Create ACL token
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1001, "test-invalid", mock.NodePolicy(acl.PolicyWrite))
+
+	arg := structs.ServerNonVoterRequest{NonVoter: true}
+	arg.Region = s1.config.Region
+	var reply structs.GenericResponse
+
+	// Try with no token and expect permission denied
+	err := msgpackrpc.CallWithCodec(codec, "Operator.ServerNonVoter", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+
+	// Try with an invalid token and expect permission denied
+	arg.AuthToken = invalidToken.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Operator.ServerNonVoter", &arg, &reply)
+	require.EqualError(err, structs.ErrPermissionDenied.Error())
+	require.NotContains(s1.serf.LocalMember().Tags, "nonvoter")
+
+	// Try with a management token and expect the server to be tagged
+	arg.AuthToken = root.SecretID
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.ServerNonVoter", &arg, &reply))
+	require.Contains(s1.serf.LocalMember().Tags, "nonvoter")
+
+	// Removing the tag rolls back a failed demotion
+	arg.NonVoter = false
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Operator.ServerNonVoter", &arg, &reply))
+	require.NotContains(s1.serf.LocalMember().Tags, "nonvoter")
+}
@@ -132,3 +132,67 @@ func (l *RaftLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net
 	}
 	return conn, err
 }
+
+// errElectionDelayed is returned for vote requests made before the leader
+// election delay has elapsed.
+var errElectionDelayed = fmt.Errorf("leader election delayed")
+
+// electionDelayTransport wraps a raft.Transport and refuses to send vote
+// requests until it is opened, so the local server can't gather the votes it
+// needs to become leader. All other traffic, including votes we cast for
+// other servers, passes through untouched.
+type electionDelayTransport struct {
+	raft.Transport
+
+	openCh   chan struct{}
+	openOnce sync.Once
+	timer    *time.Timer
+}
+
+// newElectionDelayTransport returns a transport that opens after delay or
+// when Open is called, whichever comes first.
+func newElectionDelayTransport(trans raft.Transport, delay time.Duration) *electionDelayTransport {
+	t := &electionDelayTransport{
+		Transport: trans,
+		openCh:    make(chan struct{}),
+	}
+	t.timer = time.AfterFunc(delay, t.Open)
+	return t
+}
+
+// Open allows vote requests to be sent. It is safe to call multiple times
+// and on a nil transport.
+func (t *electionDelayTransport) Open() {
+	if t == nil {
+		return
+	}
+	t.openOnce.Do(func() { close(t.openCh) })
+}
+
+// Stop stops the delay timer without opening the transport. It is safe to
+// call on a nil transport.
+func (t *electionDelayTransport) Stop() {
+	if t == nil {
+		return
+	}
+	t.timer.Stop()
+}
+
+// RequestVote fails until the transport is opened.
+func (t *electionDelayTransport) RequestVote(id raft.ServerID, target raft.ServerAddress,
+	args *raft.RequestVoteRequest, resp *raft.RequestVoteResponse) error {
+	select {
+	case <-t.openCh:
+		return t.Transport.RequestVote(id, target, args, resp)
+	default:
+		return errElectionDelayed
+	}
+}
+
+// Close closes the wrapped transport if it supports it.
+func (t *electionDelayTransport) Close() error {
+	if c, ok := t.Transport.(raft.WithClose); ok {
+		return c.Close()
+	}
+	return nil
+}
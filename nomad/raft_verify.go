@@ -0,0 +1,202 @@
+package nomad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/raft"
+)
+
+const (
+	// raftVerifyLockTimeout bounds how long VerifyRaftStore waits to open a
+	// Raft log store that is locked, such as by a running server
+	raftVerifyLockTimeout = time.Second
+
+	// raftLogsBucket is the bucket of the Raft log store holding log entries
+	raftLogsBucket = "logs"
+
+	// raftSnapshotsDir is the directory of the Raft snapshot store holding a
+	// directory per snapshot, and raftSnapshotTmpSuffix marks snapshots that
+	// are still being written
+	raftSnapshotsDir      = "snapshots"
+	raftSnapshotTmpSuffix = ".tmp"
+)
+
+// VerifyRaftStore checks the integrity of the on-disk Raft log and snapshot
+// stores. The log store is opened read-only and every entry must decode and
+// follow the previous one without gaps. Every retained snapshot must match
+// its checksum, and the oldest log entry must follow the latest snapshot so
+// the state can be rebuilt. A descriptive error is returned on corruption.
+//
+// It must be called before the server opens its Raft stores, such as on
+// startup with VerifyRaftOnStart. Servers in dev mode have no stores to
+// verify.
+func (s *Server) VerifyRaftStore() error {
+	if s.config.DevMode {
+		return nil
+	}
+
+	path := filepath.Join(s.config.DataDir, raftState)
+	snapIndex, hasSnap, err := verifyRaftSnapshots(path)
+	if err != nil {
+		return err
+	}
+
+	first, last, err := verifyRaftLogs(filepath.Join(path, "raft.db"))
+	if err != nil {
+		return err
+	}
+	if first == 0 {
+		return nil
+	}
+	switch {
+	case hasSnap && first > snapIndex+1:
+		return fmt.Errorf("raft log starts at index %d but the latest snapshot is at index %d; entries %d to %d are missing",
+			first, snapIndex, snapIndex+1, first-1)
+	case !hasSnap && first != 1:
+		return fmt.Errorf("raft log starts at index %d without a snapshot; entries 1 to %d are missing", first, first-1)
+	}
+
+	s.logger.Debug("verified raft store", "first_index", first, "last_index", last, "snapshot_index", snapIndex)
+	return nil
+}
+
+// raftSnapshotMeta is the metadata the Raft file snapshot store writes to the
+// meta.json file of each snapshot, including the CRC64 of its state.bin file.
+type raftSnapshotMeta struct {
+	raft.SnapshotMeta
+	CRC []byte
+}
+
+// verifyRaftSnapshots checks that every snapshot under path matches its
+// checksum and returns the index of the latest one, if any. The snapshot
+// directories are read directly rather than through a Raft snapshot store,
+// which would create files and skip snapshots with unreadable metadata.
+func verifyRaftSnapshots(path string) (uint64, bool, error) {
+	dir := filepath.Join(path, raftSnapshotsDir)
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("failed to list raft snapshots: %v", err)
+	}
+
+	var latest *raftSnapshotMeta
+	for _, entry := range entries {
+		// Snapshots still being written are ignored by Raft
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), raftSnapshotTmpSuffix) {
+			continue
+		}
+
+		meta, err := verifyRaftSnapshot(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return 0, false, fmt.Errorf("raft snapshot %q is corrupt: %v", entry.Name(), err)
+		}
+		if latest == nil || meta.Term > latest.Term ||
+			(meta.Term == latest.Term && meta.Index > latest.Index) {
+			latest = meta
+		}
+	}
+
+	if latest == nil {
+		return 0, false, nil
+	}
+	return latest.Index, true, nil
+}
+
+// verifyRaftSnapshot reads the metadata of the snapshot in dir and checks its
+// state matches the checksum.
+func verifyRaftSnapshot(dir string) (*raftSnapshotMeta, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "meta.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %v", err)
+	}
+	var meta raftSnapshotMeta
+	if err := json.Unmarshal(buf, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %v", err)
+	}
+	if meta.Version < raft.SnapshotVersionMin || meta.Version > raft.SnapshotVersionMax {
+		return nil, fmt.Errorf("unsupported snapshot version %d", meta.Version)
+	}
+
+	fh, err := os.Open(filepath.Join(dir, "state.bin"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state: %v", err)
+	}
+	defer fh.Close()
+
+	hash := crc64.New(crc64.MakeTable(crc64.ECMA))
+	if _, err := io.Copy(hash, fh); err != nil {
+		return nil, fmt.Errorf("failed to read state: %v", err)
+	}
+	if !bytes.Equal(meta.CRC, hash.Sum(nil)) {
+		return nil, fmt.Errorf("state at index %d doesn't match its checksum", meta.Index)
+	}
+	return &meta, nil
+}
+
+// verifyRaftLogs opens the Raft log store at path read-only and checks every
+// entry decodes and follows the previous one without gaps. The first and last
+// indexes are returned, or zeros if the store has no entries.
+func verifyRaftLogs(path string) (uint64, uint64, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  raftVerifyLockTimeout,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open raft log store %q: %v", path, err)
+	}
+	defer db.Close()
+
+	var first, last uint64
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(raftLogsBucket))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if len(k) != 8 {
+				return fmt.Errorf("raft log entry after index %d has a malformed key %x", last, k)
+			}
+			index := binary.BigEndian.Uint64(k)
+			if first != 0 && index != last+1 {
+				return fmt.Errorf("raft log entries %d to %d are missing", last+1, index-1)
+			}
+
+			var entry raft.Log
+			dec := codec.NewDecoder(bytes.NewReader(v), &codec.MsgpackHandle{})
+			if err := dec.Decode(&entry); err != nil {
+				return fmt.Errorf("raft log entry %d is corrupt: %v", index, err)
+			}
+			if entry.Index != index {
+				return fmt.Errorf("raft log entry %d is corrupt: it is stored with index %d", index, entry.Index)
+			}
+
+			if first == 0 {
+				first = index
+			}
+			last = index
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
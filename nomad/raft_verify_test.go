@@ -0,0 +1,129 @@
+package nomad
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_VerifyRaftStore(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.Bootstrap = true
+		c.DataDir = dir
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	for i := 0; i < 5; i++ {
+		req := &structs.NodeRegisterRequest{
+			Node:         mock.Node(),
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		_, _, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+		require.NoError(err)
+	}
+	require.NoError(s1.Shutdown())
+
+	// An intact store verifies
+	require.NoError(s1.VerifyRaftStore())
+
+	// Corrupt a log entry in the middle of the log
+	db, err := bolt.Open(filepath.Join(dir, raftState, "raft.db"), 0600, nil)
+	require.NoError(err)
+	var index uint64
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(raftLogsBucket))
+		var keys [][]byte
+		bucket.ForEach(func(k, v []byte) error {
+			keys = append(keys, k)
+			return nil
+		})
+		require.True(len(keys) > 2, "expected more than 2 log entries; got %d", len(keys))
+		key := keys[len(keys)/2]
+		index = binary.BigEndian.Uint64(key)
+		return bucket.Put(key, []byte{0xc1, 0xff, 0x00})
+	})
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	err = s1.VerifyRaftStore()
+	require.Error(err)
+	require.Contains(err.Error(), "corrupt")
+	require.Contains(err.Error(), "raft log entry "+strconv.FormatUint(index, 10)+" is corrupt")
+}
+
+func TestServer_VerifyRaftStore_Snapshots(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.Bootstrap = true
+		c.DataDir = dir
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.NodeRegisterRequest{
+		Node:         mock.Node(),
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	_, _, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+	require.NoError(err)
+	require.NoError(s1.raft.Snapshot().Error())
+	require.NoError(s1.Shutdown())
+
+	snapshotsDir := filepath.Join(dir, raftState, raftSnapshotsDir)
+	list := func() []string {
+		var files []string
+		err := filepath.Walk(snapshotsDir, func(path string, info os.FileInfo, err error) error {
+			files = append(files, path)
+			return err
+		})
+		require.NoError(err)
+		return files
+	}
+	snapshots, err := ioutil.ReadDir(snapshotsDir)
+	require.NoError(err)
+	require.Len(snapshots, 1)
+	snapDir := filepath.Join(snapshotsDir, snapshots[0].Name())
+
+	// An intact store verifies without creating any files
+	before := list()
+	require.NoError(s1.VerifyRaftStore())
+	require.Equal(before, list())
+
+	// Corrupt metadata isn't skipped
+	metaPath := filepath.Join(snapDir, "meta.json")
+	meta, err := ioutil.ReadFile(metaPath)
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(metaPath, []byte(`{"Version":`), 0600))
+	err = s1.VerifyRaftStore()
+	require.Error(err)
+	require.Contains(err.Error(), "raft snapshot "+strconv.Quote(snapshots[0].Name())+" is corrupt")
+	require.Contains(err.Error(), "failed to decode metadata")
+
+	// Nor is state that doesn't match its checksum
+	require.NoError(ioutil.WriteFile(metaPath, meta, 0600))
+	require.NoError(ioutil.WriteFile(filepath.Join(snapDir, "state.bin"), []byte("corrupt"), 0600))
+	err = s1.VerifyRaftStore()
+	require.Error(err)
+	require.Contains(err.Error(), "doesn't match its checksum")
+}
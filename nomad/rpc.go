@@ -19,7 +19,6 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
 
-	"github.com/hashicorp/consul/lib"
 	"github.com/hashicorp/nomad/helper/pool"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -449,7 +448,7 @@ CHECK_LEADER:
 		firstCheck = time.Now()
 	}
 	if time.Now().Sub(firstCheck) < r.config.RPCHoldTimeout {
-		jitter := lib.RandomStagger(r.config.RPCHoldTimeout / structs.JitterFraction)
+		jitter := r.rng.Stagger(r.config.RPCHoldTimeout / structs.JitterFraction)
 		select {
 		case <-time.After(jitter):
 			goto CHECK_LEADER
@@ -457,7 +456,20 @@ CHECK_LEADER:
 		}
 	}
 
-	// No leader found and hold time exceeded
+	// No leader found and hold time exceeded. Reads may be served from our
+	// possibly stale local state if configured; QueryMeta.KnownLeader will
+	// be false to flag the staleness.
+	//
+	// This also covers a leader that lost quorum: Raft steps it down once it
+	// hasn't heard from a quorum within the LeaderLeaseTimeout, so it ends up
+	// here like any other server without a leader. Reads it serves before
+	// then are within its lease, as with any read served by the leader.
+	if info.IsRead() {
+		if r.config.StaleReadOnQuorumLoss {
+			return false, nil
+		}
+		return true, structs.ErrNoQuorum
+	}
 	return true, structs.ErrNoLeader
 }
 
@@ -514,9 +526,18 @@ func (r *rpcHandler) forwardRegion(region, method string, args interface{}, repl
 		return structs.ErrNoRegionPath
 	}
 
-	// Select a random addr
+	// Select a random addr, preferring the region's leader if it has
+	// announced itself to avoid an extra hop
 	offset := rand.Intn(len(servers))
 	server := servers[offset]
+	if leader, ok := r.RemoteLeader(region); ok {
+		for _, s := range servers {
+			if s.Name == leader {
+				server = s
+				break
+			}
+		}
+	}
 	r.peerLock.RUnlock()
 
 	// Forward to remote Nomad
@@ -682,7 +703,7 @@ func (r *rpcHandler) blockingRPC(opts *blockingOptions) error {
 	}
 
 	// Apply a small amount of jitter to the request
-	opts.queryOpts.MaxQueryTime += lib.RandomStagger(opts.queryOpts.MaxQueryTime / structs.JitterFraction)
+	opts.queryOpts.MaxQueryTime += r.rng.Stagger(opts.queryOpts.MaxQueryTime / structs.JitterFraction)
 
 	// Setup a query timeout
 	ctx, cancel = context.WithTimeout(context.Background(), opts.queryOpts.MaxQueryTime)
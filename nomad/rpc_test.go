@@ -98,6 +98,94 @@ func TestRPC_forwardRegion(t *testing.T) {
 	}
 }
 
+func TestRPC_forward_QuorumLoss(t *testing.T) {
+	t.Parallel()
+
+	// run loses quorum and reads from the surviving server, either a follower
+	// or the leader that steps down once it loses contact with quorum
+	run := func(t *testing.T, staleReads, leader bool) {
+		require := require.New(t)
+
+		configure := func(c *Config) {
+			c.RPCHoldTimeout = 100 * time.Millisecond
+			c.StaleReadOnQuorumLoss = staleReads
+		}
+
+		s1 := TestServer(t, func(c *Config) {
+			if leader {
+				configure(c)
+			}
+		})
+		defer s1.Shutdown()
+		testutil.WaitForLeader(t, s1.RPC)
+
+		dir := tmpDir(t)
+		defer os.RemoveAll(dir)
+		s2 := TestServer(t, func(c *Config) {
+			c.DevMode = false
+			c.DevDisableBootstrap = true
+			c.DataDir = path.Join(dir, "node2")
+		})
+		defer s2.Shutdown()
+
+		s3 := TestServer(t, func(c *Config) {
+			c.DevMode = false
+			c.DevDisableBootstrap = true
+			c.DataDir = path.Join(dir, "node3")
+			if !leader {
+				configure(c)
+			}
+		})
+		defer s3.Shutdown()
+
+		TestJoin(t, s1, s2, s3)
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s3.numPeers()
+			return peers == 3, nil
+		}, func(err error) {
+			t.Fatalf("should have 3 peers")
+		})
+
+		// Lose quorum and wait for the survivor to notice there is no leader
+		survivor := s3
+		if leader {
+			survivor = s1
+			s2.Shutdown()
+			s3.Shutdown()
+		} else {
+			s1.Shutdown()
+			s2.Shutdown()
+		}
+		testutil.WaitForResult(func() (bool, error) {
+			return survivor.raft.Leader() == "", nil
+		}, func(err error) {
+			t.Fatalf("survivor should have no leader")
+		})
+
+		get := &structs.JobListRequest{
+			QueryOptions: structs.QueryOptions{
+				Region:    "global",
+				Namespace: structs.DefaultNamespace,
+			},
+		}
+		var resp structs.JobListResponse
+		err := survivor.RPC("Job.List", get, &resp)
+		if staleReads {
+			require.NoError(err)
+			require.False(resp.KnownLeader)
+		} else {
+			require.Error(err)
+			require.True(structs.IsErrNoQuorum(err), "unexpected error: %v", err)
+			require.True(structs.IsErrNoLeader(err))
+		}
+	}
+
+	t.Run("error", func(t *testing.T) { run(t, false, false) })
+	t.Run("stale", func(t *testing.T) { run(t, true, false) })
+	t.Run("leader error", func(t *testing.T) { run(t, false, true) })
+	t.Run("leader stale", func(t *testing.T) { run(t, true, true) })
+}
+
 func TestRPC_PlaintextRPCSucceedsWhenInUpgradeMode(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
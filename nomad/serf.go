@@ -1,10 +1,13 @@
 package nomad
 
 import (
+	"encoding/json"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -22,8 +25,96 @@ const (
 
 	// peerRetryBase is a baseline retry time
 	peerRetryBase = 1 * time.Second
+
+	// demoteTagTimeout bounds how long the leader waits to see a demoted
+	// server's non-voter tag before giving up on the demotion
+	demoteTagTimeout = 5 * time.Second
+
+	// evacuateLeaveAttempts limits how many times a server being evacuated
+	// is instructed to leave
+	evacuateLeaveAttempts = 3
+
+	// serfReservedPrefix prefixes the names of the serf user events and
+	// queries used internally by Nomad.
+	serfReservedPrefix = "nomad:"
+
+	// serfEventLeader is the name of the serf user event a newly elected
+	// leader broadcasts to announce itself to the other regions.
+	serfEventLeader = serfReservedPrefix + "leader"
+
+	// serfQueryFailedMembers is the name of the serf query used to collect
+	// the members each server's probes have found failed.
+	serfQueryFailedMembers = serfReservedPrefix + "failed-members"
 )
 
+// leaderEvent is the payload of the serfEventLeader user event.
+type leaderEvent struct {
+	// Region and Name identify the new leader by its serf member name
+	Region string
+	Name   string
+
+	// Term is the Raft term the leader was elected in. It orders
+	// announcements from the same region.
+	Term uint64
+}
+
+// remoteLeader is a leader announced by another region.
+type remoteLeader struct {
+	name string
+	term uint64
+}
+
+// queueSerfEvents moves events received from the serf cluster to the bounded
+// queue read by serfEventHandler.
+func (s *Server) queueSerfEvents() {
+	for {
+		select {
+		case e := <-s.serfEventCh:
+			s.queueSerfEvent(e)
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
+// queueSerfEvent queues an event to be handled. If the queue is full, user
+// events are dropped so a slow handler can't cause unbounded growth, while
+// member events and queries, which must not be lost, wait for room.
+func (s *Server) queueSerfEvent(e serf.Event) {
+	select {
+	case s.eventCh <- e:
+		return
+	default:
+	}
+
+	if e.EventType() == serf.EventUser {
+		atomic.AddUint64(&s.serfEventsDropped, 1)
+		metrics.IncrCounter([]string{"nomad", "serf", "events_dropped"}, 1)
+		s.logger.Warn("serf event queue full; dropping event", "event", e.String(), "queue_size", cap(s.eventCh))
+		return
+	}
+
+	atomic.AddUint64(&s.serfEventsDelayed, 1)
+	metrics.IncrCounter([]string{"nomad", "serf", "events_delayed"}, 1)
+	s.logger.Warn("serf event queue full; delaying event", "event", e.String(), "queue_size", cap(s.eventCh))
+	select {
+	case s.eventCh <- e:
+	case <-s.shutdownCh:
+	}
+}
+
+// SerfEventsDropped returns the number of serf user events dropped because
+// the event queue was full.
+func (s *Server) SerfEventsDropped() uint64 {
+	return atomic.LoadUint64(&s.serfEventsDropped)
+}
+
+// SerfEventsDelayed returns the number of serf member events and queries that
+// had to wait because the event queue was full.
+func (s *Server) SerfEventsDelayed() uint64 {
+	return atomic.LoadUint64(&s.serfEventsDelayed)
+}
+
 // serfEventHandler is used to handle events from the serf cluster
 func (s *Server) serfEventHandler() {
 	for {
@@ -38,7 +129,11 @@ func (s *Server) serfEventHandler() {
 				s.localMemberEvent(e.(serf.MemberEvent))
 			case serf.EventMemberReap:
 				s.localMemberEvent(e.(serf.MemberEvent))
-			case serf.EventMemberUpdate, serf.EventUser, serf.EventQuery: // Ignore
+			case serf.EventQuery:
+				s.handleQuery(e.(*serf.Query))
+			case serf.EventUser:
+				s.handleUserEvent(e.(serf.UserEvent))
+			case serf.EventMemberUpdate: // Ignore
 			default:
 				s.logger.Warn("unhandled serf event", "event", log.Fmt("%#v", e))
 			}
@@ -80,6 +175,12 @@ func (s *Server) nodeJoin(me serf.MemberEvent) {
 		if parts.Region == s.config.Region {
 			s.localPeers[raft.ServerAddress(parts.Addr.String())] = parts
 		}
+
+		// Wake anything waiting for the server's region
+		if s.peersJoinedCh != nil {
+			close(s.peersJoinedCh)
+			s.peersJoinedCh = nil
+		}
 		s.peerLock.Unlock()
 
 		// If we still expecting to bootstrap, may need to handle this
@@ -220,6 +321,8 @@ func (s *Server) maybeBootstrap() {
 	future := s.raft.BootstrapCluster(configuration)
 	if err := future.Error(); err != nil {
 		s.logger.Error("failed to bootstrap cluster", "error", err)
+	} else {
+		s.electionDelay.Open()
 	}
 
 	// Bootstrapping complete, or failed for some reason, don't enter this again
@@ -286,3 +389,148 @@ func (s *Server) localMemberEvent(me serf.MemberEvent) {
 		}
 	}
 }
+
+// handleUserEvent handles the serf user events used internally by Nomad.
+// Unknown events using the reserved prefix are ignored.
+func (s *Server) handleUserEvent(e serf.UserEvent) {
+	switch e.Name {
+	case serfEventLeader:
+		s.handleLeaderEvent(e.Payload)
+	default:
+		if strings.HasPrefix(e.Name, serfReservedPrefix) {
+			s.logger.Warn("ignoring unknown reserved serf user event", "event", e.Name)
+		}
+	}
+}
+
+// handleLeaderEvent records the leader announced by another region. Events
+// naming a server that isn't known to be alive in the region, or announcing
+// an older term than already known, are ignored.
+func (s *Server) handleLeaderEvent(payload []byte) {
+	var event leaderEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		s.logger.Warn("ignoring malformed leader event", "error", err)
+		return
+	}
+
+	// Our own region's leader is known through Raft
+	if event.Region == s.config.Region {
+		return
+	}
+
+	known := false
+	for _, m := range s.serf.Members() {
+		if m.Name != event.Name || m.Status != serf.StatusAlive {
+			continue
+		}
+		if ok, parts := isNomadServer(m); ok && parts.Region == event.Region {
+			known = true
+		}
+		break
+	}
+	if !known {
+		s.logger.Warn("ignoring leader event for unknown server", "region", event.Region, "server", event.Name)
+		return
+	}
+
+	s.remoteLeadersLock.Lock()
+	defer s.remoteLeadersLock.Unlock()
+	existing, ok := s.remoteLeaders[event.Region]
+	if ok && (existing.term > event.Term || existing.name == event.Name && existing.term == event.Term) {
+		return
+	}
+	s.remoteLeaders[event.Region] = remoteLeader{name: event.Name, term: event.Term}
+	s.logger.Debug("learned leader of remote region", "region", event.Region, "leader", event.Name, "term", event.Term)
+}
+
+// broadcastLeadership announces this server as the leader of its region to
+// the other regions.
+func (s *Server) broadcastLeadership() {
+	term, err := strconv.ParseUint(s.raft.Stats()["term"], 10, 64)
+	if err != nil {
+		s.logger.Error("failed to parse raft term", "error", err)
+		return
+	}
+
+	payload, err := json.Marshal(&leaderEvent{
+		Region: s.config.Region,
+		Name:   s.serf.LocalMember().Name,
+		Term:   term,
+	})
+	if err != nil {
+		s.logger.Error("failed to encode leader event", "error", err)
+		return
+	}
+	if err := s.serf.UserEvent(serfEventLeader, payload, false); err != nil {
+		s.logger.Error("failed to broadcast leadership", "error", err)
+	}
+}
+
+// broadcastLeadershipPeriodically announces this server as the leader of its
+// region immediately and then at the LeaderBroadcastInterval until leadership
+// is lost. Repeated announcements for the same term are ignored by receivers.
+func (s *Server) broadcastLeadershipPeriodically(stopCh chan struct{}) {
+	ticker := time.NewTicker(s.config.LeaderBroadcastInterval)
+	defer ticker.Stop()
+	for {
+		s.broadcastLeadership()
+		select {
+		case <-stopCh:
+			return
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RemoteLeader returns the serf member name of the leader of a remote region
+// if it has been announced. Only leaders of regions that broadcast their
+// leadership are known.
+func (s *Server) RemoteLeader(region string) (string, bool) {
+	s.remoteLeadersLock.RLock()
+	defer s.remoteLeadersLock.RUnlock()
+	leader, ok := s.remoteLeaders[region]
+	return leader.name, ok
+}
+
+// handleQuery is used to respond to the serf queries Nomad servers issue to
+// each other. Unknown queries are ignored.
+func (s *Server) handleQuery(q *serf.Query) {
+	switch q.Name {
+	case serfQueryFailedMembers:
+		var failed []string
+		for _, m := range s.serf.Members() {
+			if m.Status == serf.StatusFailed {
+				failed = append(failed, m.Name)
+			}
+		}
+		payload, err := json.Marshal(failed)
+		if err != nil {
+			s.logger.Error("failed to encode failed members", "error", err)
+			return
+		}
+		if err := q.Respond(payload); err != nil {
+			s.logger.Warn("failed to respond to failed members query", "error", err)
+		}
+	}
+}
+
+// setNonVoterTag sets or removes this server's non-voter tag in serf. Tagged
+// servers aren't promoted to voters by autopilot. The tag is lost when the
+// server restarts. Servers configured as NonVoter always keep the tag.
+func (s *Server) setNonVoterTag(nonVoter bool) error {
+	if !nonVoter && s.config.NonVoter {
+		return nil
+	}
+	tags := make(map[string]string)
+	for k, v := range s.serf.LocalMember().Tags {
+		tags[k] = v
+	}
+	if nonVoter {
+		tags["nonvoter"] = "1"
+	} else {
+		delete(tags, "nonvoter")
+	}
+	return s.serf.SetTags(tags)
+}
@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,8 +10,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/consul/lib/freeport"
+	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/testutil"
 	"github.com/hashicorp/serf/serf"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNomad_JoinPeer(t *testing.T) {
@@ -54,6 +58,80 @@ func TestNomad_JoinPeer(t *testing.T) {
 	})
 }
 
+func TestNomad_JoinDetailed(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	s2 := TestServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+	})
+	defer s2.Shutdown()
+
+	reachable := fmt.Sprintf("127.0.0.1:%d", s1.config.SerfConfig.MemberlistConfig.BindPort)
+	unreachable := fmt.Sprintf("127.0.0.1:%d", freeport.GetT(t, 1)[0])
+
+	results, err := s2.JoinDetailed([]string{unreachable, reachable})
+	require.NoError(err)
+	require.Len(results, 2)
+
+	require.Equal(unreachable, results[0].Address)
+	require.False(results[0].Joined())
+	require.Error(results[0].Error)
+
+	require.Equal(reachable, results[1].Address)
+	require.True(results[1].Joined())
+	require.NoError(results[1].Error)
+
+	// Failing to join every address is an error
+	results, err = s2.JoinDetailed([]string{unreachable})
+	require.Error(err)
+	require.Len(results, 1)
+	require.False(results[0].Joined())
+}
+
+func TestNomad_WaitForRegion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	// The local region is known immediately
+	require.NoError(s1.WaitForRegion(context.Background(), "global"))
+
+	// Waiting for an unknown region ends with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(context.DeadlineExceeded, s1.WaitForRegion(ctx, "region2"))
+
+	// Start waiting before the region2 server joins
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s1.WaitForRegion(context.Background(), "region2")
+	}()
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("returned before region2 joined: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+
+	select {
+	case err := <-errCh:
+		require.NoError(err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for region2")
+	}
+}
+
 func TestNomad_RemovePeer(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -448,3 +526,278 @@ func TestNomad_BadExpect(t *testing.T) {
 		t.Fatalf("should have 0 peers: %v", err)
 	})
 }
+
+func TestNomad_BroadcastLeadership(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	var region2 []*Server
+	for i := 0; i < 3; i++ {
+		s := TestServer(t, func(c *Config) {
+			c.Region = "region2"
+			c.BootstrapExpect = 3
+			c.DevMode = false
+			c.DevDisableBootstrap = true
+			c.DataDir = path.Join(dir, fmt.Sprintf("node%d", i))
+			c.BroadcastLeadership = true
+			c.LeaderBroadcastInterval = 100 * time.Millisecond
+		})
+		defer s.Shutdown()
+		region2 = append(region2, s)
+	}
+	TestJoin(t, s1, region2[0], region2[1], region2[2])
+
+	// leader returns the leader of region2 among the given servers
+	leader := func(servers []*Server) *Server {
+		var leader *Server
+		testutil.WaitForResult(func() (bool, error) {
+			for _, s := range servers {
+				if s.IsLeader() {
+					leader = s
+					return true, nil
+				}
+			}
+			return false, fmt.Errorf("no leader")
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+		return leader
+	}
+
+	// remoteLeaderIs waits for s1 to learn region2's leader
+	remoteLeaderIs := func(expected *Server) {
+		name := expected.LocalMember().Name
+		testutil.WaitForResult(func() (bool, error) {
+			if l, _ := s1.RemoteLeader("region2"); l != name {
+				return false, fmt.Errorf("expected leader %q; got %q", name, l)
+			}
+			return true, nil
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+
+	old := leader(region2)
+	remoteLeaderIs(old)
+
+	// Force an election by stopping the leader
+	var remaining []*Server
+	for _, s := range region2 {
+		if s != old {
+			remaining = append(remaining, s)
+		}
+	}
+	old.Shutdown()
+	remoteLeaderIs(leader(remaining))
+
+	// The local region's leader is known through Raft rather than events
+	if _, ok := s1.RemoteLeader("region1"); ok {
+		t.Fatalf("expected no announced leader for the local region")
+	}
+}
+
+func TestNomad_SerfEventBuffer(t *testing.T) {
+	t.Parallel()
+
+	// Nothing handles the queued events so flooding it must drop events
+	s := &Server{
+		eventCh: make(chan serf.Event, 4),
+		logger:  testlog.HCLogger(t),
+	}
+	for i := 0; i < 10; i++ {
+		s.queueSerfEvent(serf.UserEvent{Name: fmt.Sprintf("event-%d", i)})
+	}
+
+	if n := len(s.eventCh); n != 4 {
+		t.Fatalf("expected 4 queued events; got %d", n)
+	}
+	if n := s.SerfEventsDropped(); n != 6 {
+		t.Fatalf("expected 6 dropped events; got %d", n)
+	}
+}
+
+func TestNomad_SerfEventBuffer_MemberEvents(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{
+		eventCh:    make(chan serf.Event, 1),
+		logger:     testlog.HCLogger(t),
+		shutdownCh: make(chan struct{}),
+	}
+	s.queueSerfEvent(serf.UserEvent{Name: "user"})
+
+	// A member event waits for room rather than being dropped
+	queued := make(chan struct{})
+	join := serf.MemberEvent{Type: serf.EventMemberJoin}
+	go func() {
+		s.queueSerfEvent(join)
+		close(queued)
+	}()
+
+	testutil.WaitForResult(func() (bool, error) {
+		n := s.SerfEventsDelayed()
+		return n == 1, fmt.Errorf("expected 1 delayed event; got %d", n)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	select {
+	case <-queued:
+		t.Fatalf("member event queued while the queue was full")
+	default:
+	}
+
+	// Handling the queued event makes room for the member event
+	<-s.eventCh
+	select {
+	case <-queued:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for member event to be queued")
+	}
+	if e := <-s.eventCh; e.EventType() != serf.EventMemberJoin {
+		t.Fatalf("expected member join event; got %v", e)
+	}
+	if n := s.SerfEventsDropped(); n != 0 {
+		t.Fatalf("expected no dropped events; got %d", n)
+	}
+}
+
+func TestNomad_RegionBootstrapExpect(t *testing.T) {
+	t.Parallel()
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	// Two servers in region2 bootstrap even though three are expected by
+	// default
+	var servers []*Server
+	for i := 0; i < 2; i++ {
+		s := TestServer(t, func(c *Config) {
+			c.Region = "region2"
+			c.BootstrapExpect = 3
+			c.RegionBootstrapExpect = map[string]int{
+				"global":  3,
+				"region2": 2,
+			}
+			c.DevMode = false
+			c.DevDisableBootstrap = true
+			c.DataDir = path.Join(dir, fmt.Sprintf("node%d", i))
+		})
+		defer s.Shutdown()
+		servers = append(servers, s)
+	}
+	TestJoin(t, servers[0], servers[1])
+
+	testutil.WaitForResult(func() (bool, error) {
+		for _, s := range servers {
+			peers, err := s.numPeers()
+			if err != nil {
+				return false, err
+			}
+			if peers != 2 {
+				return false, fmt.Errorf("expected 2 peers; got %d", peers)
+			}
+		}
+		if !servers[0].IsLeader() && !servers[1].IsLeader() {
+			return false, fmt.Errorf("no leader")
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestNomad_SerfSnapshotPath(t *testing.T) {
+	t.Parallel()
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	snapshot := path.Join(dir, "serf", "snapshot")
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	s2 := TestServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+		c.SerfSnapshotPath = snapshot
+	})
+	if s2.config.SerfConfig.SnapshotPath != snapshot {
+		t.Fatalf("expected snapshot path %q; got %q", snapshot, s2.config.SerfConfig.SnapshotPath)
+	}
+	TestJoin(t, s1, s2)
+
+	testutil.WaitForResult(func() (bool, error) {
+		if n := len(s2.Members()); n != 2 {
+			return false, fmt.Errorf("expected 2 members; got %d", n)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	s2.Shutdown()
+
+	// A server restarted with the snapshot rejoins known members without
+	// being told to join
+	s3 := TestServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+		c.SerfSnapshotPath = snapshot
+	})
+	defer s3.Shutdown()
+
+	testutil.WaitForResult(func() (bool, error) {
+		for _, m := range s3.Members() {
+			if m.Name == s1.config.SerfConfig.NodeName && m.Status == serf.StatusAlive {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("%s did not rejoin %s", s3.config.NodeName, s1.config.NodeName)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestNomad_TombstoneTimeout(t *testing.T) {
+	t.Parallel()
+	s1 := TestServer(t, func(c *Config) {
+		c.TombstoneTimeout = 200 * time.Millisecond
+		c.SerfConfig.ReapInterval = 50 * time.Millisecond
+	})
+	defer s1.Shutdown()
+	if timeout := s1.config.SerfConfig.TombstoneTimeout; timeout != 200*time.Millisecond {
+		t.Fatalf("expected serf tombstone timeout of 200ms; got %v", timeout)
+	}
+
+	// Serf's default is kept if unset
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s2.Shutdown()
+	if timeout := s2.config.SerfConfig.TombstoneTimeout; timeout != serf.DefaultConfig().TombstoneTimeout {
+		t.Fatalf("expected serf's default tombstone timeout; got %v", timeout)
+	}
+	TestJoin(t, s1, s2)
+
+	testutil.WaitForResult(func() (bool, error) {
+		if members := s1.Members(); len(members) != 2 {
+			return false, fmt.Errorf("expected 2 members; got %#v", members)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The tombstone of the server that left is purged
+	s2.Leave()
+	s2.Shutdown()
+	testutil.WaitForResult(func() (bool, error) {
+		members := s1.Members()
+		if len(members) != 1 {
+			return false, fmt.Errorf("expected the left server to be purged; got %#v", members)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
@@ -3,6 +3,7 @@ package nomad
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -23,6 +24,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/nomad/command/agent/consul"
 	"github.com/hashicorp/nomad/helper/codec"
+	"github.com/hashicorp/nomad/helper/lockedrand"
 	"github.com/hashicorp/nomad/helper/pool"
 	"github.com/hashicorp/nomad/helper/stats"
 	"github.com/hashicorp/nomad/helper/tlsutil"
@@ -104,6 +106,41 @@ type Server struct {
 	raftInmem     *raft.InmemStore
 	raftTransport *raft.NetworkTransport
 
+	// raftSnapshots is the snapshot store used by raft. It records when
+	// snapshots are persisted.
+	raftSnapshots *timedSnapshotStore
+
+	// electionDelay gates our vote requests while the configured
+	// LeaderElectionDelay elapses. It is nil when no delay is configured.
+	electionDelay *electionDelayTransport
+
+	// rng drives randomized timing decisions. It is backed by
+	// Config.RandSource.
+	rng *lockedrand.Rand
+
+	// remoteLeaders are the leaders announced by other regions, keyed by
+	// region.
+	remoteLeaders     map[string]remoteLeader
+	remoteLeadersLock sync.RWMutex
+
+	// leaderTasks are the tasks run periodically while we are the leader.
+	// leaderTasksCancel stops them and is nil while we aren't the leader.
+	leaderTasks       []*leaderTask
+	leaderTasksCtx    context.Context
+	leaderTasksCancel context.CancelFunc
+	leaderTasksLock   sync.Mutex
+
+	// leaderSince is when we last acquired leadership and leaderStopCh is
+	// closed when we lose it. leaderStopCh is nil while we aren't the leader.
+	leaderSince     time.Time
+	leaderStopCh    chan struct{}
+	leaderSinceLock sync.Mutex
+
+	// discrepancies are the current disagreements between serf and Raft
+	// membership, keyed by kind and ID.
+	discrepancies     map[string]*trackedDiscrepancy
+	discrepanciesLock sync.Mutex
+
 	// autopilot is the Autopilot instance for this server.
 	autopilot *autopilot.Autopilot
 
@@ -156,6 +193,11 @@ type Server struct {
 	localPeers map[raft.ServerAddress]*serverParts
 	peerLock   sync.RWMutex
 
+	// peersJoinedCh is closed and cleared when a server is added to peers to
+	// wake anything waiting for a region to become known. It is created on
+	// demand and guarded by peerLock.
+	peersJoinedCh chan struct{}
+
 	// serf is the Serf cluster containing only Nomad
 	// servers. This is used for multi-region federation
 	// and automatic clustering within regions.
@@ -166,8 +208,15 @@ type Server struct {
 	// join/leave from the region.
 	reconcileCh chan serf.Member
 
-	// eventCh is used to receive events from the serf cluster
-	eventCh chan serf.Event
+	// serfEventCh receives events from the serf cluster which are queued
+	// on eventCh to be handled
+	serfEventCh chan serf.Event
+	eventCh     chan serf.Event
+
+	// serfEventsDropped and serfEventsDelayed are the number of serf events
+	// dropped or delayed because eventCh was full. Accessed with atomics.
+	serfEventsDropped uint64
+	serfEventsDelayed uint64
 
 	// BlockedEvals is used to manage evaluations that are blocked on node
 	// capacity changes.
@@ -251,8 +300,13 @@ type endpoints struct {
 // NewServer is used to construct a new Nomad server from the
 // configuration, potentially returning an error
 func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error) {
-	// Check the protocol version
-	if err := config.CheckVersion(); err != nil {
+	// Use the number of servers expected in our region if overridden
+	if expect, ok := config.RegionBootstrapExpect[config.Region]; ok {
+		atomic.StoreInt32(&config.BootstrapExpect, int32(expect))
+	}
+
+	// Check the configuration
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 
@@ -289,6 +343,8 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 	s := &Server{
 		config:        config,
 		consulCatalog: consulCatalog,
+		rng:           lockedrand.New(config.RandSource),
+		remoteLeaders: make(map[string]remoteLeader),
 		connPool:      pool.NewPool(logger, serverRPCCache, serverMaxStreams, tlsWrap),
 		logger:        logger,
 		tlsWrap:       tlsWrap,
@@ -298,7 +354,8 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 		peers:         make(map[string][]*serverParts),
 		localPeers:    make(map[raft.ServerAddress]*serverParts),
 		reconcileCh:   make(chan serf.Member, 32),
-		eventCh:       make(chan serf.Event, 256),
+		serfEventCh:   make(chan serf.Event),
+		eventCh:       make(chan serf.Event, config.SerfEventBuffer),
 		evalBroker:    evalBroker,
 		blockedEvals:  NewBlockedEvals(evalBroker, logger),
 		rpcTLS:        incomingTLS,
@@ -347,7 +404,8 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 	}
 
 	// Initialize the wan Serf
-	s.serf, err = s.setupSerf(config.SerfConfig, s.eventCh, serfSnapshot)
+	go s.queueSerfEvents()
+	s.serf, err = s.setupSerf(config.SerfConfig, s.serfEventCh, serfSnapshot)
 	if err != nil {
 		s.Shutdown()
 		s.logger.Error("failed to start serf WAN", "error", err)
@@ -387,6 +445,9 @@ func NewServer(config *Config, consulCatalog consul.CatalogAPI) (*Server, error)
 	// Start ingesting events for Serf
 	go s.serfEventHandler()
 
+	// Compare serf and Raft membership
+	go s.monitorMembership()
+
 	// start the RPC listener for the server
 	s.startRPCListener()
 
@@ -536,6 +597,9 @@ func (s *Server) Shutdown() error {
 		s.serf.Shutdown()
 	}
 
+	// Stop the election delay timer so it doesn't outlive the server
+	s.electionDelay.Stop()
+
 	if s.raft != nil {
 		s.raftTransport.Close()
 		s.raftLayer.Close()
@@ -581,6 +645,13 @@ func (s *Server) IsShutdown() bool {
 
 // Leave is used to prepare for a graceful shutdown of the server
 func (s *Server) Leave() error {
+	return s.leave(false)
+}
+
+// leave removes the server from the cluster. If voluntary is set the server
+// isn't shutting down, so leadership is held until MinLeadershipInterval has
+// elapsed before leaving.
+func (s *Server) leave(voluntary bool) error {
 	s.logger.Info("server starting leave")
 	s.left = true
 
@@ -598,6 +669,12 @@ func (s *Server) Leave() error {
 	// not the leader, then we should issue our leave intention and wait to be removed
 	// for some sane period of time.
 	isLeader := s.IsLeader()
+	if isLeader && voluntary {
+		if err := s.waitMinLeadershipInterval(); err != nil {
+			s.logger.Warn("stopped waiting for minimum leadership interval", "error", err)
+			isLeader = s.IsLeader()
+		}
+	}
 	if isLeader && numPeers > 1 {
 		minRaftProtocol, err := s.autopilot.MinRaftProtocol()
 		if err != nil {
@@ -673,6 +750,418 @@ func (s *Server) Leave() error {
 	return nil
 }
 
+// EvacuateRegion is used to decommission every server in a region. It may be
+// called on any server: the request is forwarded to the leader of the region
+// through the Operator.EvacuateRegion RPC, so with ACLs enabled the anonymous
+// policy must allow it. At least one other region must remain in the cluster.
+func (s *Server) EvacuateRegion(region string) error {
+	args := &structs.EvacuateRegionRequest{
+		WriteRequest: structs.WriteRequest{Region: region},
+	}
+	var reply structs.GenericResponse
+	return s.RPC("Operator.EvacuateRegion", args, &reply)
+}
+
+// evacuateRegion decommissions every server in our region. It must be called
+// on the leader, and authToken is passed on to authorize the instructions sent
+// to the other servers.
+//
+// The vendored raft library can't transfer leadership, and since every server
+// in the region is decommissioned none would remain to take it over, so the
+// leader keeps leadership until it's the last server standing. Nothing is
+// changed until MinLeadershipInterval has elapsed since we acquired
+// leadership. The alive followers are instructed to leave the gossip pool
+// through the Operator.ServerLeave RPC, and the servers are then removed from
+// the Raft configuration one at a time in the quorum-safe order of
+// evacuationOrder. Once the remaining configuration holds no other voter the
+// leader gives up leadership by gracefully leaving itself.
+func (s *Server) evacuateRegion(authToken string) error {
+	if !s.IsLeader() {
+		return raft.ErrNotLeader
+	}
+	region := s.config.Region
+
+	// Make sure another region survives the evacuation
+	remaining := false
+	var servers []serf.Member
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid {
+			continue
+		}
+		if parts.Region != region {
+			if parts.Status == serf.StatusAlive {
+				remaining = true
+			}
+			continue
+		}
+		if parts.ID == s.config.NodeID {
+			continue
+		}
+		if parts.Status == serf.StatusAlive || parts.Status == serf.StatusFailed {
+			servers = append(servers, member)
+		}
+	}
+	if !remaining {
+		return fmt.Errorf("cannot evacuate region %q: no other region has alive servers", region)
+	}
+
+	// Evacuating gives up leadership of the region
+	if err := s.waitMinLeadershipInterval(); err != nil {
+		return fmt.Errorf("cannot evacuate region %q: %v", region, err)
+	}
+
+	s.logger.Info("evacuating region", "region", region, "num_servers", len(servers)+1)
+
+	// Instruct the alive followers to leave serf, retrying any that fail
+	args := &structs.ServerLeaveRequest{
+		WriteRequest: structs.WriteRequest{
+			Region:    region,
+			AuthToken: authToken,
+		},
+	}
+	for _, member := range servers {
+		_, parts := isNomadServer(member)
+		if parts.Status != serf.StatusAlive {
+			continue
+		}
+		var err error
+		for attempt := 0; attempt < evacuateLeaveAttempts; attempt++ {
+			var reply structs.GenericResponse
+			if err = s.forwardServer(parts, "Operator.ServerLeave", args, &reply); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			s.logger.Warn("failed to instruct server to leave", "server", member.Name, "error", err)
+		}
+	}
+
+	// Remove the followers from raft while we still have quorum. Those that
+	// left are also removed as their leave is reconciled, which is harmless.
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	for _, member := range evacuationOrder(servers, future.Configuration()) {
+		_, parts := isNomadServer(member)
+		if err := s.removeRaftPeer(member, parts); err != nil {
+			return fmt.Errorf("failed to remove server %q from raft: %v", member.Name, err)
+		}
+		s.logger.Info("removed evacuated server from raft", "server", member.Name)
+	}
+
+	// Only leave once no other voter could be left without a leader, such as
+	// a server that joined during the evacuation
+	future = s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	for _, server := range future.Configuration().Servers {
+		if server.Suffrage == raft.Voter && server.ID != raft.ServerID(s.config.NodeID) &&
+			server.Address != s.raftTransport.LocalAddr() {
+			return fmt.Errorf("cannot finish evacuating region %q: server %q is still a voter", region, server.ID)
+		}
+	}
+	return s.leave(true)
+}
+
+// evacuationOrder returns the order in which the servers of an evacuated
+// region, other than the leader, are removed from the given Raft
+// configuration. Failed servers are removed first, then alive servers that
+// don't vote, and finally alive voters. Removing a server that can't vote or
+// respond never costs the remaining servers their quorum, and once only alive
+// voters remain each removal leaves the rest able to commit the next one.
+func evacuationOrder(members []serf.Member, config raft.Configuration) []serf.Member {
+	rank := func(m serf.Member) int {
+		_, parts := isNomadServer(m)
+		if parts.Status != serf.StatusAlive {
+			return 0
+		}
+		addr := raft.ServerAddress((&net.TCPAddr{IP: m.Addr, Port: parts.Port}).String())
+		for _, server := range config.Servers {
+			if server.ID == raft.ServerID(parts.ID) || server.Address == addr {
+				if server.Suffrage == raft.Voter {
+					return 2
+				}
+				break
+			}
+		}
+		return 1
+	}
+
+	ordered := make([]serf.Member, len(members))
+	copy(ordered, members)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+	return ordered
+}
+
+// DemoteVoter converts the server with the given node ID from a raft voter to a
+// non-voter so it stops counting toward quorum while still replicating the
+// log. It may only be called on the leader, and goes through the
+// Operator.DemoteVoter RPC, so with ACLs enabled the anonymous policy must
+// allow it.
+func (s *Server) DemoteVoter(nodeID string) error {
+	if !s.IsLeader() {
+		return raft.ErrNotLeader
+	}
+	args := &structs.DemoteVoterRequest{
+		NodeID:       nodeID,
+		WriteRequest: structs.WriteRequest{Region: s.config.Region},
+	}
+	var reply structs.GenericResponse
+	return s.RPC("Operator.DemoteVoter", args, &reply)
+}
+
+// demoteVoter demotes the server with the given node ID to a non-voter. It
+// must be called on the leader, and authToken is passed on to authorize the
+// instructions sent to the server.
+//
+// The server is first instructed to tag itself as a non-voter through the
+// Operator.ServerNonVoter RPC so autopilot doesn't promote it again, and the
+// tag is removed if the demotion fails. Demotion is refused if the remaining
+// voters that are alive couldn't form a quorum.
+//
+// Demoting the leader itself makes raft step it down once the new
+// configuration is committed, transferring leadership to one of the remaining
+// voters. As that voluntarily gives up leadership it is deferred until
+// MinLeadershipInterval has elapsed.
+func (s *Server) demoteVoter(nodeID, authToken string) error {
+	if !s.IsLeader() {
+		return raft.ErrNotLeader
+	}
+	if nodeID == s.config.NodeID {
+		if err := s.waitMinLeadershipInterval(); err != nil {
+			return err
+		}
+	}
+
+	var target *serf.Member
+	var targetParts *serverParts
+	alive := make(map[raft.ServerID]struct{})
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid || parts.Region != s.config.Region {
+			continue
+		}
+		if parts.ID == nodeID {
+			m := member
+			target, targetParts = &m, parts
+		}
+		if parts.Status == serf.StatusAlive {
+			alive[raft.ServerID(parts.ID)] = struct{}{}
+			alive[raft.ServerID(parts.Addr.String())] = struct{}{}
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("unknown server %q", nodeID)
+	}
+
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+
+	// Make sure the remaining voters that are alive form a quorum
+	var targetID raft.ServerID
+	voters, aliveVoters := 0, 0
+	for _, server := range future.Configuration().Servers {
+		if server.ID == raft.ServerID(nodeID) || server.Address == raft.ServerAddress(targetParts.Addr.String()) {
+			if server.Suffrage != raft.Voter {
+				return fmt.Errorf("server %q is not a voter", nodeID)
+			}
+			targetID = server.ID
+			continue
+		}
+		if server.Suffrage != raft.Voter {
+			continue
+		}
+		voters++
+		if _, ok := alive[server.ID]; ok {
+			aliveVoters++
+		}
+	}
+	if targetID == "" {
+		return fmt.Errorf("server %q is not a raft peer", nodeID)
+	}
+	if quorum := voters/2 + 1; aliveVoters < quorum {
+		return fmt.Errorf("demoting server %q would leave %d alive voters, fewer than the quorum of %d",
+			nodeID, aliveVoters, quorum)
+	}
+
+	// Tag the server as a non-voter before demoting it so autopilot can't
+	// promote it again in between
+	if err := s.setServerNonVoterTag(targetParts, true, authToken); err != nil {
+		return fmt.Errorf("failed to instruct server %q to tag itself as a non-voter: %v", target.Name, err)
+	}
+
+	limit := time.Now().Add(demoteTagTimeout)
+	for !s.hasNonVoterTag(nodeID) {
+		if time.Now().After(limit) {
+			s.untagNonVoter(target.Name, targetParts, authToken)
+			return fmt.Errorf("timed out waiting for server %q to be tagged as a non-voter", target.Name)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := s.raft.DemoteVoter(targetID, 0, 0).Error(); err != nil {
+		s.untagNonVoter(target.Name, targetParts, authToken)
+		return fmt.Errorf("failed to demote server %q: %v", target.Name, err)
+	}
+	s.logger.Info("demoted server to non-voter", "server", target.Name)
+	return nil
+}
+
+// setServerNonVoterTag instructs the given server to set or remove its
+// non-voter tag. Our own tag is set directly.
+func (s *Server) setServerNonVoterTag(parts *serverParts, nonVoter bool, authToken string) error {
+	if parts.ID == s.config.NodeID {
+		return s.setNonVoterTag(nonVoter)
+	}
+	args := &structs.ServerNonVoterRequest{
+		NonVoter: nonVoter,
+		WriteRequest: structs.WriteRequest{
+			Region:    s.config.Region,
+			AuthToken: authToken,
+		},
+	}
+	var reply structs.GenericResponse
+	return s.forwardServer(parts, "Operator.ServerNonVoter", args, &reply)
+}
+
+// untagNonVoter rolls back tagging a server as a non-voter after its demotion
+// failed so autopilot may promote it again.
+func (s *Server) untagNonVoter(name string, parts *serverParts, authToken string) {
+	if err := s.setServerNonVoterTag(parts, false, authToken); err != nil {
+		s.logger.Error("failed to remove non-voter tag after failed demotion", "server", name, "error", err)
+	}
+}
+
+// hasNonVoterTag returns whether the server with the given node ID is tagged as
+// a non-voter in our view of serf.
+func (s *Server) hasNonVoterTag(nodeID string) bool {
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if valid && parts.ID == nodeID {
+			return parts.NonVoter
+		}
+	}
+	return false
+}
+
+// FollowerLag returns how many Raft log entries the follower with the given
+// node ID is behind the leader. It may only be called on the leader.
+func (s *Server) FollowerLag(nodeID string) (uint64, error) {
+	if !s.IsLeader() {
+		return 0, raft.ErrNotLeader
+	}
+	if nodeID == s.config.NodeID {
+		return 0, fmt.Errorf("server %q is the leader", nodeID)
+	}
+
+	var follower *serf.Member
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if valid && parts.Region == s.config.Region && parts.ID == nodeID {
+			follower = &member
+			break
+		}
+	}
+	if follower == nil {
+		return 0, fmt.Errorf("unknown follower %q", nodeID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RaftTimeout)
+	defer cancel()
+	stats, ok := s.statsFetcher.Fetch(ctx, []serf.Member{*follower})[nodeID]
+	if !ok {
+		return 0, fmt.Errorf("failed to retrieve raft stats for follower %q", nodeID)
+	}
+
+	lastIndex := s.raft.LastIndex()
+	if stats.LastIndex >= lastIndex {
+		return 0, nil
+	}
+	return lastIndex - stats.LastIndex, nil
+}
+
+// ReplicationState returns the leader's view of raft replication to each
+// follower in the region, keyed by node ID. Non-leaders forward the request to
+// the leader through the Status.ReplicationState RPC, so with ACLs enabled the
+// anonymous policy must allow operator read.
+func (s *Server) ReplicationState() (map[string]structs.ReplState, error) {
+	args := &structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: s.config.Region,
+		},
+	}
+	var reply structs.ReplicationStateResponse
+	if err := s.RPC("Status.ReplicationState", args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Followers, nil
+}
+
+// replicationState builds the replication state of each follower. Raft doesn't
+// expose the leader's replication internals, so they are approximated from the
+// raft stats of the followers: a follower's last log index stands in for its
+// match index and its last contact with the leader for the leader's last
+// contact with it. Followers whose stats can't be fetched are omitted. It may
+// only be called on the leader.
+func (s *Server) replicationState() (map[string]structs.ReplState, error) {
+	if !s.IsLeader() {
+		return nil, raft.ErrNotLeader
+	}
+
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, err
+	}
+	inRaft := make(map[string]struct{})
+	for _, server := range future.Configuration().Servers {
+		inRaft[string(server.ID)] = struct{}{}
+		inRaft[string(server.Address)] = struct{}{}
+	}
+
+	var followers []serf.Member
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid || parts.Region != s.config.Region || parts.ID == s.config.NodeID {
+			continue
+		}
+		_, hasID := inRaft[parts.ID]
+		_, hasAddr := inRaft[parts.Addr.String()]
+		if hasID || hasAddr {
+			followers = append(followers, member)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RaftTimeout)
+	defer cancel()
+	stats := s.statsFetcher.Fetch(ctx, followers)
+
+	now := time.Now()
+	lastIndex := s.raft.LastIndex()
+	state := make(map[string]structs.ReplState, len(stats))
+	for id, stat := range stats {
+		match := stat.LastIndex
+		if match > lastIndex {
+			match = lastIndex
+		}
+		repl := structs.ReplState{
+			MatchIndex: match,
+			NextIndex:  match + 1,
+		}
+		if contact, err := time.ParseDuration(stat.LastContact); err == nil {
+			repl.LastContact = now.Add(-contact)
+		}
+		state[id] = repl
+	}
+	return state, nil
+}
+
 // Reload handles a config reload specific to server-only configuration. Not
 // all config fields can handle a reload.
 func (s *Server) Reload(newConfig *Config) error {
@@ -767,7 +1256,7 @@ func (s *Server) setupBootstrapHandler() error {
 			// `bootstrap_expect`.
 			raftPeers, err := s.numPeers()
 			if err != nil {
-				peersTimeout.Reset(peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor))
+				peersTimeout.Reset(peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor))
 				return nil
 			}
 
@@ -776,7 +1265,7 @@ func (s *Server) setupBootstrapHandler() error {
 			// Consul.  Let the normal timeout-based strategy
 			// take over.
 			if raftPeers >= int(bootstrapExpect) {
-				peersTimeout.Reset(peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor))
+				peersTimeout.Reset(peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor))
 				return nil
 			}
 		}
@@ -786,7 +1275,7 @@ func (s *Server) setupBootstrapHandler() error {
 
 		dcs, err := s.consulCatalog.Datacenters()
 		if err != nil {
-			peersTimeout.Reset(peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor))
+			peersTimeout.Reset(peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor))
 			return fmt.Errorf("server.nomad: unable to query Consul datacenters: %v", err)
 		}
 		if len(dcs) > 2 {
@@ -835,13 +1324,13 @@ func (s *Server) setupBootstrapHandler() error {
 
 		if len(nomadServerServices) == 0 {
 			if len(mErr.Errors) > 0 {
-				peersTimeout.Reset(peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor))
+				peersTimeout.Reset(peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor))
 				return mErr.ErrorOrNil()
 			}
 
 			// Log the error and return nil so future handlers
 			// can attempt to register the `nomad` service.
-			pollInterval := peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor)
+			pollInterval := peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor)
 			s.logger.Trace("no Nomad Servers advertising Nomad service in Consul datacenters", "service_name", nomadServerServiceName, "datacenters", dcs, "retry", pollInterval)
 			peersTimeout.Reset(pollInterval)
 			return nil
@@ -849,7 +1338,7 @@ func (s *Server) setupBootstrapHandler() error {
 
 		numServersContacted, err := s.Join(nomadServerServices)
 		if err != nil {
-			peersTimeout.Reset(peersPollInterval + lib.RandomStagger(peersPollInterval/peersPollJitterFactor))
+			peersTimeout.Reset(peersPollInterval + s.rng.Stagger(peersPollInterval/peersPollJitterFactor))
 			return fmt.Errorf("contacted %d Nomad Servers: %v", numServersContacted, err)
 		}
 
@@ -1088,6 +1577,16 @@ func (s *Server) setupRaft() error {
 		s.config.LogOutput)
 	s.raftTransport = trans
 
+	// Wrap the transport so we can't win an election until the delay has
+	// elapsed. Bootstrapping a new cluster, either below or once the expected
+	// number of servers have joined, opens the gate immediately since there
+	// are no peers to wait for.
+	var raftTrans raft.Transport = trans
+	if s.config.LeaderElectionDelay > 0 {
+		s.electionDelay = newElectionDelayTransport(trans, s.config.LeaderElectionDelay)
+		raftTrans = s.electionDelay
+	}
+
 	// Make sure we set the Logger.
 	logger := s.logger.StandardLogger(&log.StandardLoggerOptions{InferLevels: true})
 	s.config.RaftConfig.Logger = logger
@@ -1119,6 +1618,12 @@ func (s *Server) setupRaft() error {
 			return err
 		}
 
+		if s.config.VerifyRaftOnStart {
+			if err := s.VerifyRaftStore(); err != nil {
+				return fmt.Errorf("raft store failed verification: %v", err)
+			}
+		}
+
 		// Create the BoltDB backend
 		store, err := raftboltdb.NewBoltStore(filepath.Join(path, "raft.db"))
 		if err != nil {
@@ -1213,6 +1718,9 @@ func (s *Server) setupRaft() error {
 				log, stable, snap, trans, configuration); err != nil {
 				return err
 			}
+			if s.config.Bootstrap || (s.config.DevMode && !s.config.DevDisableBootstrap) {
+				s.electionDelay.Open()
+			}
 		}
 	}
 
@@ -1222,7 +1730,8 @@ func (s *Server) setupRaft() error {
 	s.leaderCh = leaderCh
 
 	// Setup the Raft store
-	s.raft, err = raft.NewRaft(s.config.RaftConfig, s.fsm, log, stable, snap, trans)
+	s.raftSnapshots = newTimedSnapshotStore(snap)
+	s.raft, err = raft.NewRaft(s.config.RaftConfig, s.fsm, log, stable, s.raftSnapshots, raftTrans)
 	if err != nil {
 		return err
 	}
@@ -1265,8 +1774,12 @@ func (s *Server) setupSerf(conf *serf.Config, ch chan serf.Event, path string) (
 	conf.MemberlistConfig.LogOutput = nil
 	conf.LogOutput = nil
 	conf.EventCh = ch
-	if !s.config.DevMode {
+	if s.config.SerfSnapshotPath != "" {
+		conf.SnapshotPath = s.config.SerfSnapshotPath
+	} else if !s.config.DevMode {
 		conf.SnapshotPath = filepath.Join(s.config.DataDir, path)
+	}
+	if conf.SnapshotPath != "" {
 		if err := ensurePath(conf.SnapshotPath, false); err != nil {
 			return nil, err
 		}
@@ -1278,6 +1791,9 @@ func (s *Server) setupSerf(conf *serf.Config, ch chan serf.Event, path string) (
 	// allow for convergence in 99.9% of nodes in a 10 node cluster
 	conf.LeavePropagateDelay = 1 * time.Second
 	conf.Merge = &serfMergeDelegate{}
+	if s.config.TombstoneTimeout > 0 {
+		conf.TombstoneTimeout = s.config.TombstoneTimeout
+	}
 
 	// Until Nomad supports this fully, we disable automatic resolution.
 	// When enabled, the Serf gossip may just turn off if we are the minority
@@ -1333,6 +1849,117 @@ func (s *Server) numPeers() (int, error) {
 	return len(configuration.Servers), nil
 }
 
+// QuorumInfo describes the voters of the Raft cluster and whether enough of
+// them are reachable to commit writes.
+type QuorumInfo struct {
+	// Voters is the number of voters in the Raft configuration
+	Voters int
+
+	// Quorum is the number of voters needed to elect a leader and commit
+	// writes
+	Quorum int
+
+	// Reachable is the number of voters that are alive in serf, including
+	// the local server if it's a voter
+	Reachable int
+
+	// Leader is the address of the current leader, or empty if there is none
+	Leader string
+
+	// Met is whether a quorum of voters is reachable and a leader is known,
+	// meaning the cluster can accept writes
+	Met bool
+}
+
+// QuorumStatus returns this server's view of the Raft quorum. Voters are
+// considered reachable when their serf member is alive.
+func (s *Server) QuorumStatus() (QuorumInfo, error) {
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return QuorumInfo{}, err
+	}
+
+	alive := make(map[string]struct{})
+	for _, member := range s.serf.Members() {
+		valid, parts := isNomadServer(member)
+		if !valid || parts.Region != s.config.Region || parts.Status != serf.StatusAlive {
+			continue
+		}
+		alive[parts.ID] = struct{}{}
+		alive[parts.Addr.String()] = struct{}{}
+	}
+
+	var info QuorumInfo
+	for _, server := range future.Configuration().Servers {
+		if server.Suffrage != raft.Voter {
+			continue
+		}
+		info.Voters++
+
+		_, idOK := alive[string(server.ID)]
+		_, addrOK := alive[string(server.Address)]
+		if idOK || addrOK {
+			info.Reachable++
+		}
+	}
+	info.Quorum = info.Voters/2 + 1
+	info.Leader = string(s.raft.Leader())
+	info.Met = info.Voters > 0 && info.Reachable >= info.Quorum && info.Leader != ""
+	return info, nil
+}
+
+// RaftConfigurationIndex returns the Raft log index of the latest
+// configuration. It advances whenever the membership of the Raft cluster
+// changes, so it can be polled to detect changes without comparing full
+// configurations.
+func (s *Server) RaftConfigurationIndex() (uint64, error) {
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return 0, err
+	}
+	return future.Index(), nil
+}
+
+// ClusterVersion returns a version of this server's view of the cluster that
+// increases whenever committed state or the Raft membership changes. It is
+// the sum of the applied and configuration indexes, so it can be used for
+// optimistic concurrency without tracking both.
+func (s *Server) ClusterVersion() (uint64, error) {
+	configIndex, err := s.RaftConfigurationIndex()
+	if err != nil {
+		return 0, err
+	}
+	return s.raft.AppliedIndex() + configIndex, nil
+}
+
+// PendingApplyCount returns how many Raft log entries have not yet been
+// applied to the FSM. This includes entries waiting to be committed or
+// dispatched, entries queued for the FSM, and the entry being applied. A
+// persistently high count indicates a slow FSM.
+func (s *Server) PendingApplyCount() uint64 {
+	var pending uint64
+	if last, applied := s.raft.LastIndex(), s.raft.AppliedIndex(); last > applied {
+		pending = last - applied
+	}
+
+	// Raft counts entries as applied once queued for the FSM
+	if queued, err := strconv.ParseUint(s.raft.Stats()["fsm_pending"], 10, 64); err == nil {
+		pending += queued
+	}
+	if atomic.LoadInt32(&s.fsm.applying) == 1 {
+		pending++
+	}
+	return pending
+}
+
+// ReadSnapshot returns a point-in-time view of the FSM state for in-process
+// consumers such as external read models. Taking it doesn't block writes and
+// later writes aren't reflected in it. Its LatestIndex is the index it was
+// taken at. The snapshot must only be read, never modified.
+func (s *Server) ReadSnapshot() (*state.StateSnapshot, error) {
+	return s.fsm.State().Snapshot()
+}
+
 // IsLeader checks if this server is the cluster leader
 func (s *Server) IsLeader() bool {
 	return s.raft.State() == raft.Leader
@@ -1345,6 +1972,42 @@ func (s *Server) Join(addrs []string) (int, error) {
 	return s.serf.Join(addrs, true)
 }
 
+// JoinResult is the outcome of joining a single address with JoinDetailed.
+type JoinResult struct {
+	// Address is the address that was joined
+	Address string
+
+	// Error is the error joining the address or nil if it was joined
+	Error error
+}
+
+// Joined returns true if the address was successfully joined.
+func (r *JoinResult) Joined() bool {
+	return r.Error == nil
+}
+
+// JoinDetailed joins the gossip ring like Join but attempts each address
+// individually and returns a result per address, in order, so callers can
+// retry only the addresses that failed. An error is only returned if no
+// address could be joined.
+func (s *Server) JoinDetailed(addrs []string) ([]JoinResult, error) {
+	results := make([]JoinResult, len(addrs))
+	joined := 0
+	for i, addr := range addrs {
+		results[i].Address = addr
+		if _, err := s.serf.Join([]string{addr}, true); err != nil {
+			results[i].Error = err
+			continue
+		}
+		joined++
+	}
+
+	if joined == 0 && len(addrs) > 0 {
+		return results, fmt.Errorf("failed to join any of %d addresses", len(addrs))
+	}
+	return results, nil
+}
+
 // LocalMember is used to return the local node
 func (s *Server) LocalMember() serf.Member {
 	return s.serf.LocalMember()
@@ -1355,11 +2018,136 @@ func (s *Server) Members() []serf.Member {
 	return s.serf.Members()
 }
 
+// AllMembers returns the members of the serf cluster grouped by region. Nomad
+// servers from every region gossip in the same serf pool, so this is a view of
+// the entire federated cluster. Members without a region tag are omitted.
+func (s *Server) AllMembers() map[string][]serf.Member {
+	members := make(map[string][]serf.Member)
+	for _, m := range s.serf.Members() {
+		region, ok := m.Tags["region"]
+		if !ok {
+			continue
+		}
+		members[region] = append(members[region], m)
+	}
+	return members
+}
+
+// PeerPair is a pair of servers with asymmetric connectivity: From considers
+// To alive while To considers From failed, so From can reach To but not the
+// reverse.
+type PeerPair struct {
+	From string
+	To   string
+}
+
+// AsymmetricPeers collects every server's view of which members its serf
+// probes have found failed and returns the pairs of servers that disagree
+// about whether each other is reachable. A warning is logged for each pair
+// found. Servers that don't respond in time are skipped.
+func (s *Server) AsymmetricPeers() []PeerPair {
+	pairs := findAsymmetricPeers(s.failedMemberViews())
+	for _, p := range pairs {
+		s.logger.Warn("detected asymmetric connectivity between servers",
+			"reachable_from", p.From, "unreachable_from", p.To)
+	}
+	return pairs
+}
+
+// failedMemberViews returns the members each server considers failed, keyed
+// by the server's member name. The views are collected with a serf query, so
+// only servers the query and their response could reach are included.
+func (s *Server) failedMemberViews() map[string][]string {
+	views := make(map[string][]string)
+	resp, err := s.serf.Query(serfQueryFailedMembers, nil, &serf.QueryParam{
+		FilterTags: map[string]string{"role": "nomad"},
+	})
+	if err != nil {
+		s.logger.Error("failed to query failed members of peers", "error", err)
+		return views
+	}
+	for r := range resp.ResponseCh() {
+		var failed []string
+		if err := json.Unmarshal(r.Payload, &failed); err != nil {
+			s.logger.Debug("ignoring malformed failed members response", "peer", r.From, "error", err)
+			continue
+		}
+		views[r.From] = failed
+	}
+	return views
+}
+
+// findAsymmetricPeers returns the pairs of servers where the first considers
+// the second alive but the second considers the first failed. views maps each
+// server to the members it considers failed; servers without a view are
+// skipped. The result is sorted.
+func findAsymmetricPeers(views map[string][]string) []PeerPair {
+	var pairs []PeerPair
+	for to, failed := range views {
+		for _, from := range failed {
+			fromFailed, ok := views[from]
+			if !ok || from == to {
+				continue
+			}
+			reachable := true
+			for _, name := range fromFailed {
+				if name == to {
+					reachable = false
+					break
+				}
+			}
+			if reachable {
+				pairs = append(pairs, PeerPair{From: from, To: to})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].From != pairs[j].From {
+			return pairs[i].From < pairs[j].From
+		}
+		return pairs[i].To < pairs[j].To
+	})
+	return pairs
+}
+
 // RemoveFailedNode is used to remove a failed node from the cluster
 func (s *Server) RemoveFailedNode(node string) error {
 	return s.serf.RemoveFailedNode(node)
 }
 
+// ForceLeave removes a failed member from the serf cluster and, when called on
+// the leader, also removes it from the Raft configuration so the dead server
+// no longer counts towards quorum. Followers only issue the serf force-leave;
+// the leader removes the Raft peer once it reconciles the member.
+func (s *Server) ForceLeave(name string) error {
+	var member *serf.Member
+	for _, m := range s.serf.Members() {
+		if m.Name == name {
+			member = &m
+			break
+		}
+	}
+	if member == nil {
+		return fmt.Errorf("unknown member %q", name)
+	}
+	if member.Status == serf.StatusAlive {
+		return fmt.Errorf("member %q is alive and must leave gracefully", name)
+	}
+
+	if err := s.serf.RemoveFailedNode(name); err != nil {
+		return fmt.Errorf("failed to force leave member %q: %v", name, err)
+	}
+
+	valid, parts := isNomadServer(*member)
+	if !valid || parts.Region != s.config.Region || !s.IsLeader() {
+		return nil
+	}
+	if err := s.removeRaftPeer(*member, parts); err != nil {
+		return fmt.Errorf("failed to remove server %q from raft: %v", name, err)
+	}
+	return nil
+}
+
 // KeyManager returns the Serf keyring manager
 func (s *Server) KeyManager() *serf.KeyManager {
 	return s.serf.KeyManager()
@@ -1403,6 +2191,32 @@ func (s *Server) Regions() []string {
 	return regions
 }
 
+// WaitForRegion blocks until at least one server in the given region is known
+// through gossip. An error is returned if the context is done or the server
+// shuts down first.
+func (s *Server) WaitForRegion(ctx context.Context, region string) error {
+	for {
+		s.peerLock.Lock()
+		if len(s.peers[region]) > 0 {
+			s.peerLock.Unlock()
+			return nil
+		}
+		if s.peersJoinedCh == nil {
+			s.peersJoinedCh = make(chan struct{})
+		}
+		joinedCh := s.peersJoinedCh
+		s.peerLock.Unlock()
+
+		select {
+		case <-joinedCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.shutdownCh:
+			return fmt.Errorf("server shutdown while waiting for region %q", region)
+		}
+	}
+}
+
 // RPC is used to make a local RPC call
 func (s *Server) RPC(method string, args interface{}, reply interface{}) error {
 	codec := &codec.InmemCodec{
@@ -3,6 +3,7 @@ package nomad
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path"
 	"strings"
@@ -13,9 +14,12 @@ import (
 	"github.com/hashicorp/nomad/helper/testlog"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/nomad/structs/config"
 	"github.com/hashicorp/nomad/testutil"
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/serf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -207,6 +211,607 @@ func TestServer_Regions(t *testing.T) {
 	})
 }
 
+func TestServer_AllMembers(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s2.Shutdown()
+
+	TestJoin(t, s1, s2)
+	testutil.WaitForResult(func() (bool, error) {
+		members := s1.AllMembers()
+		if len(members["region1"]) != 1 || len(members["region2"]) != 1 {
+			return false, fmt.Errorf("unexpected members: %v", members)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	members := s1.AllMembers()
+	require.Len(members, 2)
+	require.Equal(s1.LocalMember().Name, members["region1"][0].Name)
+	require.Equal(s2.LocalMember().Name, members["region2"][0].Name)
+}
+
+func TestServer_AsymmetricPeers(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s2.Shutdown()
+
+	TestJoin(t, s1, s2)
+	testutil.WaitForResult(func() (bool, error) {
+		n := len(s1.Members())
+		return n == 2, fmt.Errorf("expected 2 members; got %d", n)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Both servers report their view through the serf query
+	views := s1.failedMemberViews()
+	require.Len(views, 2)
+	require.Empty(views[s1.LocalMember().Name])
+	require.Empty(views[s2.LocalMember().Name])
+
+	// Servers that can reach each other aren't reported
+	require.Empty(s1.AsymmetricPeers())
+}
+
+func TestServer_findAsymmetricPeers(t *testing.T) {
+	t.Parallel()
+
+	// s1 and s2 can reach each other, s3 can reach s1 but s1 can't reach s3
+	// and s4 hasn't reported its view
+	views := map[string][]string{
+		"s1": {"s3"},
+		"s2": nil,
+		"s3": {"s4"},
+	}
+	require.Equal(t, []PeerPair{{From: "s3", To: "s1"}}, findAsymmetricPeers(views))
+}
+
+func TestServer_EvacuateRegion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+		c.BootstrapExpect = 2
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+		c.BootstrapExpect = 2
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForLeader(t, s2.RPC)
+	testutil.WaitForResult(func() (bool, error) {
+		for _, s := range []*Server{s2, s3} {
+			peers, err := s.numPeers()
+			if err != nil {
+				return false, err
+			}
+			if peers != 2 {
+				return false, fmt.Errorf("expected 2 region2 peers; got %d", peers)
+			}
+		}
+		if s2.IsLeader() == s3.IsLeader() {
+			return false, fmt.Errorf("expected a single region2 leader")
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	leader, follower := s2, s3
+	if !leader.IsLeader() {
+		leader, follower = s3, s2
+	}
+
+	// Evacuating from another region is forwarded to the region's leader
+	require.NoError(s1.EvacuateRegion("region2"))
+
+	// The follower was removed from region2's raft configuration before the
+	// leader left, so the leader is the only server left in it
+	future := leader.raft.GetConfiguration()
+	require.NoError(future.Error())
+	servers := future.Configuration().Servers
+	require.Len(servers, 1)
+	require.Equal(leader.raftTransport.LocalAddr(), servers[0].Address)
+	require.NotEqual(follower.raftTransport.LocalAddr(), servers[0].Address)
+
+	// Both region2 servers left serf
+	testutil.WaitForResult(func() (bool, error) {
+		for _, m := range s1.Members() {
+			if m.Tags["region"] == "region2" && m.Status != serf.StatusLeft {
+				return false, fmt.Errorf("member %q has status %v", m.Name, m.Status)
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// The remaining region kept its quorum
+	require.True(s1.IsLeader())
+	peers, err := s1.numPeers()
+	require.NoError(err)
+	require.Equal(1, peers)
+}
+
+func TestServer_EvacuationOrder(t *testing.T) {
+	t.Parallel()
+
+	member := func(id string, ip byte, status serf.MemberStatus) serf.Member {
+		return serf.Member{
+			Name:   id,
+			Addr:   net.IP([]byte{127, 0, 0, ip}),
+			Status: status,
+			Tags: map[string]string{
+				"role":     "nomad",
+				"region":   "region1",
+				"dc":       "dc1",
+				"port":     "4647",
+				"vsn":      "1",
+				"raft_vsn": "3",
+				"id":       id,
+				"build":    "0.9.0",
+			},
+		}
+	}
+	members := []serf.Member{
+		member("voter1", 1, serf.StatusAlive),
+		member("failed", 2, serf.StatusFailed),
+		member("nonvoter", 3, serf.StatusAlive),
+		member("voter2", 4, serf.StatusAlive),
+		member("unknown", 5, serf.StatusAlive),
+	}
+	config := raft.Configuration{
+		Servers: []raft.Server{
+			{Suffrage: raft.Voter, ID: "voter1", Address: "127.0.0.1:4647"},
+			{Suffrage: raft.Voter, ID: "failed", Address: "127.0.0.2:4647"},
+			{Suffrage: raft.Nonvoter, ID: "nonvoter", Address: "127.0.0.3:4647"},
+			// Added by address by an older raft protocol
+			{Suffrage: raft.Voter, ID: "127.0.0.4:4647", Address: "127.0.0.4:4647"},
+		},
+	}
+
+	var order []string
+	for _, m := range evacuationOrder(members, config) {
+		order = append(order, m.Name)
+	}
+	require.Equal(t, []string{"failed", "nonvoter", "unknown", "voter1", "voter2"}, order)
+}
+
+func TestServer_FollowerLag(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+	for _, s := range []*Server{s1, s2, s3} {
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s.numPeers()
+			return peers == 3, fmt.Errorf("expected 3 peers; got %d", peers)
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+	require.True(s1.IsLeader())
+
+	// Only the leader can report lag, and only for known followers
+	_, err := s2.FollowerLag(s3.config.NodeID)
+	require.Equal(raft.ErrNotLeader, err)
+	_, err = s1.FollowerLag("unknown")
+	require.Error(err)
+	_, err = s1.FollowerLag(s1.config.NodeID)
+	require.Error(err)
+
+	// Write some entries and check healthy followers keep up
+	for i := 0; i < 5; i++ {
+		req := &structs.NodeRegisterRequest{
+			Node:         mock.Node(),
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		_, _, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+		require.NoError(err)
+	}
+
+	for _, follower := range []*Server{s2, s3} {
+		testutil.WaitForResult(func() (bool, error) {
+			lag, err := s1.FollowerLag(follower.config.NodeID)
+			if err != nil {
+				return false, err
+			}
+			return lag == 0, fmt.Errorf("expected no lag; got %d", lag)
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+
+		for i := 0; i < 5; i++ {
+			lag, err := s1.FollowerLag(follower.config.NodeID)
+			require.NoError(err)
+			require.True(lag <= 1, "unexpected lag %d", lag)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestServer_ReplicationState(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+	for _, s := range []*Server{s1, s2, s3} {
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s.numPeers()
+			return peers == 3, fmt.Errorf("expected 3 peers; got %d", peers)
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+	require.True(s1.IsLeader())
+
+	for i := 0; i < 5; i++ {
+		req := &structs.NodeRegisterRequest{
+			Node:         mock.Node(),
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		_, _, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+		require.NoError(err)
+	}
+
+	// Followers forward to the leader so every server sees the same followers
+	for _, s := range []*Server{s1, s2, s3} {
+		testutil.WaitForResult(func() (bool, error) {
+			state, err := s.ReplicationState()
+			if err != nil {
+				return false, err
+			}
+			if len(state) != 2 {
+				return false, fmt.Errorf("expected 2 followers; got %#v", state)
+			}
+
+			lastIndex := s1.raft.LastIndex()
+			for _, follower := range []*Server{s2, s3} {
+				repl, ok := state[follower.config.NodeID]
+				if !ok {
+					return false, fmt.Errorf("follower %q missing from %#v", follower.config.NodeID, state)
+				}
+				if repl.MatchIndex > lastIndex || lastIndex-repl.MatchIndex > 1 {
+					return false, fmt.Errorf("expected match index close to %d; got %d", lastIndex, repl.MatchIndex)
+				}
+				if repl.NextIndex != repl.MatchIndex+1 {
+					return false, fmt.Errorf("expected next index %d; got %d", repl.MatchIndex+1, repl.NextIndex)
+				}
+				if repl.LastContact.IsZero() || time.Since(repl.LastContact) > time.Minute {
+					return false, fmt.Errorf("implausible last contact %v", repl.LastContact)
+				}
+			}
+			return true, nil
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	}
+}
+
+func TestServer_DemoteVoter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	suffrage := func() (map[raft.ServerID]raft.ServerSuffrage, error) {
+		future := s1.raft.GetConfiguration()
+		if err := future.Error(); err != nil {
+			return nil, err
+		}
+		suffrage := make(map[raft.ServerID]raft.ServerSuffrage)
+		for _, server := range future.Configuration().Servers {
+			suffrage[server.ID] = server.Suffrage
+		}
+		return suffrage, nil
+	}
+
+	// Wait for autopilot to promote the joined servers
+	testutil.WaitForResult(func() (bool, error) {
+		servers, err := suffrage()
+		if err != nil {
+			return false, err
+		}
+		voters := 0
+		for _, s := range servers {
+			if s == raft.Voter {
+				voters++
+			}
+		}
+		return voters == 3, fmt.Errorf("expected 3 voters; got %v", servers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	require.True(s1.IsLeader())
+
+	// Only the leader can demote
+	require.Equal(raft.ErrNotLeader, s2.DemoteVoter(s3.config.NodeID))
+	require.Error(s1.DemoteVoter("unknown"))
+
+	require.NoError(s1.DemoteVoter(s3.config.NodeID))
+	servers, err := suffrage()
+	require.NoError(err)
+	require.Equal(raft.Nonvoter, servers[raft.ServerID(s3.config.NodeID)])
+	require.Equal(raft.Voter, servers[raft.ServerID(s2.config.NodeID)])
+
+	// Already demoted
+	require.Error(s1.DemoteVoter(s3.config.NodeID))
+
+	// Autopilot doesn't promote the demoted server again
+	time.Sleep(5 * s1.config.AutopilotInterval)
+	servers, err = suffrage()
+	require.NoError(err)
+	require.Equal(raft.Nonvoter, servers[raft.ServerID(s3.config.NodeID)])
+
+	// Quorum is retained and the demoted server keeps replicating
+	req := &structs.NodeRegisterRequest{
+		Node:         mock.Node(),
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	_, index, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+	require.NoError(err)
+	require.True(s1.IsLeader())
+	testutil.WaitForResult(func() (bool, error) {
+		applied := s3.raft.AppliedIndex()
+		return applied >= index, fmt.Errorf("expected index %d to be applied; got %d", index, applied)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestServer_RaftConfigurationIndex(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	before, err := s1.RaftConfigurationIndex()
+	require.NoError(err)
+	require.NotZero(before)
+
+	// Adding a peer changes the configuration
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+
+	testutil.WaitForResult(func() (bool, error) {
+		peers, _ := s1.numPeers()
+		return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	after, err := s1.RaftConfigurationIndex()
+	require.NoError(err)
+	require.True(after > before, "expected index to advance past %d; got %d", before, after)
+}
+
+func TestServer_ClusterVersion(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	initial, err := s1.ClusterVersion()
+	require.NoError(err)
+	require.NotZero(initial)
+
+	// Applying state advances the version
+	req := &structs.NodeRegisterRequest{
+		Node:         mock.Node(),
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	_, _, err = s1.raftApply(structs.NodeRegisterRequestType, req)
+	require.NoError(err)
+
+	applied, err := s1.ClusterVersion()
+	require.NoError(err)
+	require.True(applied > initial, "expected version to advance past %d; got %d", initial, applied)
+
+	// Changing membership advances the version
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+	TestJoin(t, s1, s2)
+
+	testutil.WaitForResult(func() (bool, error) {
+		peers, _ := s1.numPeers()
+		return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	joined, err := s1.ClusterVersion()
+	require.NoError(err)
+	require.True(joined > applied, "expected version to advance past %d; got %d", applied, joined)
+}
+
+func TestServer_ForceLeave(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+	testutil.WaitForResult(func() (bool, error) {
+		peers, _ := s1.numPeers()
+		return peers == 3, fmt.Errorf("expected 3 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	require.True(s1.IsLeader())
+
+	// Alive and unknown members can't be force left
+	name := s3.LocalMember().Name
+	require.Error(s1.ForceLeave(name))
+	require.Error(s1.ForceLeave("unknown"))
+
+	// Kill s3 without leaving and wait for it to be detected as failed
+	s3.Shutdown()
+	testutil.WaitForResult(func() (bool, error) {
+		for _, m := range s1.Members() {
+			if m.Name == name && m.Status != serf.StatusFailed {
+				return false, fmt.Errorf("member %q has status %v", name, m.Status)
+			}
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	require.NoError(s1.ForceLeave(name))
+
+	// s3 is gone from raft immediately and has left serf
+	future := s1.raft.GetConfiguration()
+	require.NoError(future.Error())
+	require.Len(future.Configuration().Servers, 2)
+	for _, server := range future.Configuration().Servers {
+		require.NotEqual(raft.ServerID(s3.config.NodeID), server.ID)
+	}
+	for _, m := range s1.Members() {
+		if m.Name == name {
+			require.Equal(serf.StatusLeft, m.Status)
+		}
+	}
+}
+
 func TestServer_Reload_Vault(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, func(c *Config) {
@@ -548,3 +1153,168 @@ func TestServer_InvalidSchedulers(t *testing.T) {
 	require.NotNil(err)
 	require.Contains(err.Error(), "foo")
 }
+
+func TestServer_PendingApplyCount(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Hold the state store's write transaction so applies block in the FSM
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go s1.fsm.State().WithWriteTransaction(func(state.Txn) error {
+		close(holding)
+		<-release
+		return fmt.Errorf("abort")
+	})
+	<-holding
+
+	errCh := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			req := &structs.NodeRegisterRequest{
+				Node:         mock.Node(),
+				WriteRequest: structs.WriteRequest{Region: "global"},
+			}
+			_, _, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+			errCh <- err
+		}()
+	}
+
+	testutil.WaitForResult(func() (bool, error) {
+		pending := s1.PendingApplyCount()
+		return pending == 2, fmt.Errorf("expected 2 pending applies; got %d", pending)
+	}, func(err error) {
+		close(release)
+		t.Fatalf("err: %v", err)
+	})
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		require.NoError(<-errCh)
+	}
+	require.Zero(s1.PendingApplyCount())
+}
+
+func TestServer_ReadSnapshot(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+
+	register := func(node *structs.Node) uint64 {
+		req := &structs.NodeRegisterRequest{
+			Node:         node,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		_, index, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+		require.NoError(err)
+		return index
+	}
+
+	node1 := mock.Node()
+	index := register(node1)
+
+	snap, err := s1.ReadSnapshot()
+	require.NoError(err)
+
+	// Writes after the snapshot was taken aren't reflected in it
+	node2 := mock.Node()
+	register(node2)
+	req := &structs.NodeUpdateStatusRequest{
+		NodeID:       node1.ID,
+		Status:       structs.NodeStatusDown,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	_, _, err = s1.raftApply(structs.NodeUpdateStatusRequestType, req)
+	require.NoError(err)
+
+	snapIndex, err := snap.LatestIndex()
+	require.NoError(err)
+	require.Equal(index, snapIndex)
+
+	out, err := snap.NodeByID(nil, node1.ID)
+	require.NoError(err)
+	require.NotNil(out)
+	require.Equal(structs.NodeStatusReady, out.Status)
+
+	out, err = snap.NodeByID(nil, node2.ID)
+	require.NoError(err)
+	require.Nil(out)
+
+	// The live state has moved on
+	out, err = s1.fsm.State().NodeByID(nil, node1.ID)
+	require.NoError(err)
+	require.Equal(structs.NodeStatusDown, out.Status)
+	out, err = s1.fsm.State().NodeByID(nil, node2.ID)
+	require.NoError(err)
+	require.NotNil(out)
+}
+
+func TestServer_QuorumStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, nil)
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node3")
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	testutil.WaitForResult(func() (bool, error) {
+		info, err := s1.QuorumStatus()
+		if err != nil {
+			return false, err
+		}
+		return info.Voters == 3 && info.Reachable == 3, fmt.Errorf("expected 3 reachable voters; got %#v", info)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	info, err := s1.QuorumStatus()
+	require.NoError(err)
+	require.Equal(2, info.Quorum)
+	require.NotEmpty(info.Leader)
+	require.True(info.Met)
+
+	// Losing two of the three voters loses quorum
+	s2.Shutdown()
+	s3.Shutdown()
+	testutil.WaitForResult(func() (bool, error) {
+		info, err := s1.QuorumStatus()
+		if err != nil {
+			return false, err
+		}
+		if info.Met {
+			return false, fmt.Errorf("expected quorum to be lost; got %#v", info)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+	info, err = s1.QuorumStatus()
+	require.NoError(err)
+	require.Equal(3, info.Voters)
+	require.Equal(2, info.Quorum)
+	require.False(info.Met)
+}
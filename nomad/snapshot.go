@@ -0,0 +1,94 @@
+package nomad
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// ForceSnapshot takes a snapshot of the FSM immediately instead of waiting
+// for the Raft snapshot threshold, returning once it has been persisted to
+// the snapshot store. Every server snapshots its own FSM so it may be called
+// on followers as well as the leader. If nothing was committed since the
+// last snapshot that snapshot is current and nil is returned.
+func (s *Server) ForceSnapshot() error {
+	// Raft only refuses empty snapshots, so skip snapshotting the same index
+	// again
+	last, err := strconv.ParseUint(s.raft.Stats()["last_snapshot_index"], 10, 64)
+	if err == nil && last != 0 && last >= s.raft.AppliedIndex() {
+		return nil
+	}
+
+	start := time.Now()
+	if err := s.raft.Snapshot().Error(); err != nil {
+		if err == raft.ErrNothingNewToSnapshot {
+			return nil
+		}
+		return err
+	}
+	s.logger.Info("forced snapshot", "duration", time.Since(start))
+	return nil
+}
+
+// LastSnapshotTime returns when this server last persisted a snapshot of its
+// FSM, whether taken automatically, forced, or installed from the leader. It
+// is zero if no snapshot was persisted since the server started.
+func (s *Server) LastSnapshotTime() time.Time {
+	if s.raftSnapshots == nil {
+		return time.Time{}
+	}
+	return s.raftSnapshots.lastSnapshot()
+}
+
+// timedSnapshotStore wraps a Raft snapshot store to record when snapshots
+// are successfully persisted.
+type timedSnapshotStore struct {
+	raft.SnapshotStore
+
+	last     time.Time
+	lastLock sync.Mutex
+}
+
+// newTimedSnapshotStore wraps store to record when snapshots are persisted.
+func newTimedSnapshotStore(store raft.SnapshotStore) *timedSnapshotStore {
+	return &timedSnapshotStore{SnapshotStore: store}
+}
+
+// Create begins a snapshot whose sink records the time once it is closed
+// successfully.
+func (t *timedSnapshotStore) Create(version raft.SnapshotVersion, index, term uint64,
+	configuration raft.Configuration, configurationIndex uint64, trans raft.Transport) (raft.SnapshotSink, error) {
+
+	sink, err := t.SnapshotStore.Create(version, index, term, configuration, configurationIndex, trans)
+	if err != nil {
+		return nil, err
+	}
+	return &timedSnapshotSink{SnapshotSink: sink, store: t}, nil
+}
+
+// lastSnapshot returns when a snapshot was last persisted.
+func (t *timedSnapshotStore) lastSnapshot() time.Time {
+	t.lastLock.Lock()
+	defer t.lastLock.Unlock()
+	return t.last
+}
+
+// timedSnapshotSink records the time in its store when it is closed
+// successfully.
+type timedSnapshotSink struct {
+	raft.SnapshotSink
+	store *timedSnapshotStore
+}
+
+func (t *timedSnapshotSink) Close() error {
+	if err := t.SnapshotSink.Close(); err != nil {
+		return err
+	}
+
+	t.store.lastLock.Lock()
+	t.store.last = time.Now()
+	t.store.lastLock.Unlock()
+	return nil
+}
@@ -0,0 +1,61 @@
+package nomad
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ForceSnapshot(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.Bootstrap = true
+		c.DataDir = dir
+	})
+	defer s1.Shutdown()
+	testutil.WaitForLeader(t, s1.RPC)
+	require.True(s1.LastSnapshotTime().IsZero())
+
+	node := mock.Node()
+	req := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	_, index, err := s1.raftApply(structs.NodeRegisterRequestType, req)
+	require.NoError(err)
+
+	before := time.Now()
+	require.NoError(s1.ForceSnapshot())
+	last := s1.LastSnapshotTime()
+	require.False(last.Before(before), "last snapshot at %v; forced at %v", last, before)
+	require.True(time.Since(last) < time.Minute)
+
+	// The snapshot reflects the applied state
+	snapshots, err := s1.raftSnapshots.List()
+	require.NoError(err)
+	require.NotEmpty(snapshots)
+	require.True(snapshots[0].Index >= index, "snapshot index %d is before %d", snapshots[0].Index, index)
+
+	_, r, err := s1.raftSnapshots.Open(snapshots[0].ID)
+	require.NoError(err)
+	fsm := testFSM(t)
+	require.NoError(fsm.Restore(r))
+	out, err := fsm.State().NodeByID(nil, node.ID)
+	require.NoError(err)
+	require.NotNil(out)
+
+	// Forcing another snapshot succeeds even if nothing new was committed
+	require.NoError(s1.ForceSnapshot())
+	require.NoError(s1.ForceSnapshot())
+	require.False(s1.LastSnapshotTime().Before(last))
+}
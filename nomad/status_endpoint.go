@@ -3,11 +3,13 @@ package nomad
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 
 	log "github.com/hashicorp/go-hclog"
 
 	"github.com/hashicorp/consul/agent/consul/autopilot"
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -77,6 +79,31 @@ func (s *Status) Peers(args *structs.GenericRequest, reply *[]string) error {
 	return nil
 }
 
+// ReplicationState returns the leader's view of raft replication to each of
+// its followers
+func (s *Status) ReplicationState(args *structs.GenericRequest, reply *structs.ReplicationStateResponse) error {
+	if args.Region == "" {
+		args.Region = s.srv.config.Region
+	}
+	if done, err := s.srv.forward("Status.ReplicationState", args, args, reply); done {
+		return err
+	}
+
+	// Check operator read permissions
+	if aclObj, err := s.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowOperatorRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	followers, err := s.srv.replicationState()
+	if err != nil {
+		return err
+	}
+	reply.Followers = followers
+	return nil
+}
+
 // Members return the list of servers in a cluster that a particular server is
 // aware of
 func (s *Status) Members(args *structs.GenericRequest, reply *structs.ServerMembersResponse) error {
@@ -113,6 +140,67 @@ func (s *Status) Members(args *structs.GenericRequest, reply *structs.ServerMemb
 	return nil
 }
 
+// ServiceHealth aggregates the status of a service's checks across all of the
+// nodes registered in Consul. The reply contains the worst status of any check
+// along with the status of the checks on each node.
+func (s *Status) ServiceHealth(args *structs.ServiceHealthRequest, reply *structs.ServiceHealthResponse) error {
+	if done, err := s.srv.forward("Status.ServiceHealth", args, args, reply); done {
+		return err
+	}
+
+	// Check node read permissions
+	if aclObj, err := s.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	if args.ServiceName == "" {
+		return errors.New("Must provide the ServiceName")
+	}
+
+	health := s.srv.config.ConsulHealth
+	if health == nil {
+		return errors.New("Consul health queries are not configured")
+	}
+
+	checks, _, err := health.Checks(args.ServiceName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to query checks of service %q: %v", args.ServiceName, err)
+	}
+
+	// Group the checks by node
+	byNode := make(map[string]api.HealthChecks)
+	for _, check := range checks {
+		byNode[check.Node] = append(byNode[check.Node], check)
+	}
+
+	reply.Status = checks.AggregatedStatus()
+	reply.Nodes = make([]*structs.NodeServiceHealth, 0, len(byNode))
+	for node, nodeChecks := range byNode {
+		nodeHealth := &structs.NodeServiceHealth{
+			Node:   node,
+			Status: nodeChecks.AggregatedStatus(),
+			Checks: make([]*structs.ServiceCheckHealth, len(nodeChecks)),
+		}
+		for i, check := range nodeChecks {
+			nodeHealth.Checks[i] = &structs.ServiceCheckHealth{
+				CheckID: check.CheckID,
+				Name:    check.Name,
+				Status:  check.Status,
+				Output:  check.Output,
+			}
+		}
+		reply.Nodes = append(reply.Nodes, nodeHealth)
+	}
+	sort.Slice(reply.Nodes, func(i, j int) bool {
+		return reply.Nodes[i].Node < reply.Nodes[j].Node
+	})
+
+	s.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
+
 // Used by Autopilot to query the raft stats of the local server.
 func (s *Status) RaftStats(args struct{}, reply *autopilot.ServerStats) error {
 	stats := s.srv.raft.Stats()
@@ -131,6 +219,24 @@ func (s *Status) RaftStats(args struct{}, reply *autopilot.ServerStats) error {
 	return nil
 }
 
+// MemberStatuses is used to query the local server's view of the status of
+// each serf member.
+func (s *Status) MemberStatuses(args *structs.GenericRequest, reply *map[string]string) error {
+	// Check node read permissions
+	if aclObj, err := s.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	statuses := make(map[string]string)
+	for _, m := range s.srv.Members() {
+		statuses[m.Name] = m.Status.String()
+	}
+	*reply = statuses
+	return nil
+}
+
 // HasNodeConn returns whether the server has a connection to the requested
 // Node.
 func (s *Status) HasNodeConn(args *structs.NodeSpecificRequest, reply *structs.NodeConnQueryResponse) error {
@@ -1,14 +1,19 @@
 package nomad
 
 import (
+	"os"
+	"path"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/consul/api"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 	"github.com/hashicorp/nomad/acl"
 	"github.com/hashicorp/nomad/helper/uuid"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
+	"github.com/hashicorp/serf/serf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -172,6 +177,113 @@ func TestStatusMembers_ACL(t *testing.T) {
 	}
 }
 
+func TestStatusMemberStatuses_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	assert := assert.New(t)
+	state := s1.fsm.State()
+
+	// Create the namespace policy and tokens
+	validToken := mock.CreatePolicyAndToken(t, state, 1001, "test-valid", mock.NodePolicy(acl.PolicyRead))
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid", mock.AgentPolicy(acl.PolicyRead))
+
+	arg := &structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     "global",
+			AllowStale: true,
+		},
+	}
+	expected := map[string]string{
+		s1.LocalMember().Name: serf.StatusAlive.String(),
+	}
+
+	// Try without a token and expect failure
+	{
+		var out map[string]string
+		err := msgpackrpc.CallWithCodec(codec, "Status.MemberStatuses", arg, &out)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with an invalid token and expect failure
+	{
+		arg.AuthToken = invalidToken.SecretID
+		var out map[string]string
+		err := msgpackrpc.CallWithCodec(codec, "Status.MemberStatuses", arg, &out)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with a valid token
+	{
+		arg.AuthToken = validToken.SecretID
+		var out map[string]string
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "Status.MemberStatuses", arg, &out))
+		assert.Equal(expected, out)
+	}
+
+	// Try with a management token
+	{
+		arg.AuthToken = root.SecretID
+		var out map[string]string
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "Status.MemberStatuses", arg, &out))
+		assert.Equal(expected, out)
+	}
+}
+
+func TestStatusReplicationState_ACL(t *testing.T) {
+	t.Parallel()
+	s1, root := TestACLServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	assert := assert.New(t)
+	state := s1.fsm.State()
+
+	// Create the operator policy and tokens
+	validToken := mock.CreatePolicyAndToken(t, state, 1001, "test-valid", mock.OperatorPolicy(acl.PolicyRead))
+	invalidToken := mock.CreatePolicyAndToken(t, state, 1003, "test-invalid", mock.NodePolicy(acl.PolicyRead))
+
+	arg := &structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+
+	// Try without a token and expect failure
+	{
+		var out structs.ReplicationStateResponse
+		err := msgpackrpc.CallWithCodec(codec, "Status.ReplicationState", arg, &out)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with an invalid token and expect failure
+	{
+		arg.AuthToken = invalidToken.SecretID
+		var out structs.ReplicationStateResponse
+		err := msgpackrpc.CallWithCodec(codec, "Status.ReplicationState", arg, &out)
+		assert.NotNil(err)
+		assert.Equal(err.Error(), structs.ErrPermissionDenied.Error())
+	}
+
+	// Try with a valid token
+	{
+		arg.AuthToken = validToken.SecretID
+		var out structs.ReplicationStateResponse
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "Status.ReplicationState", arg, &out))
+	}
+
+	// Try with a root token
+	{
+		arg.AuthToken = root.SecretID
+		var out structs.ReplicationStateResponse
+		assert.Nil(msgpackrpc.CallWithCodec(codec, "Status.ReplicationState", arg, &out))
+	}
+}
+
 func TestStatus_HasClientConn(t *testing.T) {
 	t.Parallel()
 	s1 := TestServer(t, nil)
@@ -205,3 +317,92 @@ func TestStatus_HasClientConn(t *testing.T) {
 	require.True(out3.Connected)
 	require.NotZero(out3.Established)
 }
+
+// fakeConsulHealth is a HealthAPI returning a fixed set of checks.
+type fakeConsulHealth struct {
+	checks api.HealthChecks
+
+	calls int
+	mu    sync.Mutex
+}
+
+func (f *fakeConsulHealth) Checks(service string, q *api.QueryOptions) (api.HealthChecks, *api.QueryMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	var checks api.HealthChecks
+	for _, check := range f.checks {
+		if check.ServiceName == service {
+			checks = append(checks, check)
+		}
+	}
+	return checks, &api.QueryMeta{}, nil
+}
+
+func (f *fakeConsulHealth) numCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestStatus_ServiceHealth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	checks := api.HealthChecks{
+		{Node: "node1", CheckID: "web1", Name: "web", ServiceName: "web", Status: api.HealthPassing},
+		{Node: "node2", CheckID: "web2", Name: "web", ServiceName: "web", Status: api.HealthCritical, Output: "connection refused"},
+		{Node: "node2", CheckID: "web3", Name: "web-alive", ServiceName: "web", Status: api.HealthPassing},
+		{Node: "node3", CheckID: "web4", Name: "web", ServiceName: "web", Status: api.HealthWarning},
+		{Node: "node3", CheckID: "db1", Name: "db", ServiceName: "db", Status: api.HealthPassing},
+	}
+	health1 := &fakeConsulHealth{checks: checks}
+	s1 := TestServer(t, func(c *Config) {
+		c.ConsulHealth = health1
+	})
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	health2 := &fakeConsulHealth{checks: checks}
+	s2 := TestServer(t, func(c *Config) {
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+		c.ConsulHealth = health2
+	})
+	defer s2.Shutdown()
+
+	TestJoin(t, s1, s2)
+	testutil.WaitForLeader(t, s2.RPC)
+
+	// Query the follower so the request is forwarded to the leader
+	codec := rpcClient(t, s2)
+	args := &structs.ServiceHealthRequest{
+		ServiceName: "web",
+		QueryOptions: structs.QueryOptions{
+			Region: "global",
+		},
+	}
+	var resp structs.ServiceHealthResponse
+	require.NoError(msgpackrpc.CallWithCodec(codec, "Status.ServiceHealth", args, &resp))
+	require.Equal(1, health1.numCalls())
+	require.Zero(health2.numCalls())
+
+	require.Equal(api.HealthCritical, resp.Status)
+	require.Len(resp.Nodes, 3)
+	require.Equal("node1", resp.Nodes[0].Node)
+	require.Equal(api.HealthPassing, resp.Nodes[0].Status)
+	require.Equal("node2", resp.Nodes[1].Node)
+	require.Equal(api.HealthCritical, resp.Nodes[1].Status)
+	require.Len(resp.Nodes[1].Checks, 2)
+	require.Equal("connection refused", resp.Nodes[1].Checks[0].Output)
+	require.Equal("node3", resp.Nodes[2].Node)
+	require.Equal(api.HealthWarning, resp.Nodes[2].Status)
+	require.Len(resp.Nodes[2].Checks, 1)
+
+	// A service name is required
+	args.ServiceName = ""
+	require.Error(msgpackrpc.CallWithCodec(codec, "Status.ServiceHealth", args, &resp))
+}
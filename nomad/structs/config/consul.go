@@ -88,6 +88,42 @@ type ConsulConfig struct {
 	// ClientAutoJoin enables Nomad servers to find addresses of Nomad servers
 	// and register with them
 	ClientAutoJoin *bool `mapstructure:"client_auto_join"`
+
+	// CheckCacheMaxAge enables persisting script check results on clients
+	// and seeding them after a restart if they are younger than this. Zero
+	// disables the cache.
+	CheckCacheMaxAge time.Duration `mapstructure:"check_cache_max_age"`
+
+	// MaxChecksPerAlloc limits the number of checks a single allocation may
+	// register on a client. Zero means unlimited.
+	MaxChecksPerAlloc int `mapstructure:"max_checks_per_alloc"`
+
+	// ShutdownCheckRetries is how many times clients retry a failed final run
+	// of a script check on shutdown before reporting its status.
+	ShutdownCheckRetries int `mapstructure:"shutdown_check_retries"`
+
+	// CheckShutdownBehavior is what clients do with script checks on
+	// shutdown: "drain" runs them once more, "deregister" deregisters them,
+	// and "leave" does neither. Empty drains.
+	CheckShutdownBehavior string `mapstructure:"check_shutdown_behavior"`
+
+	// CheckErrorSummaryInterval is how often clients summarize repeated
+	// identical errors updating a script check instead of logging each one.
+	CheckErrorSummaryInterval time.Duration `mapstructure:"check_error_summary_interval"`
+
+	// CheckWorkers is how many script checks clients run at once. Runs wait
+	// in a bounded queue for a free worker. Zero runs every check as soon as
+	// it is due.
+	CheckWorkers int `mapstructure:"check_workers"`
+
+	// CheckTracing enables logging the duration of each script check
+	// execution and heartbeat on clients at the trace log level.
+	CheckTracing *bool `mapstructure:"check_tracing"`
+
+	// CheckWebhookAllowlist is the hosts, optionally with a port, script
+	// check webhooks may be sent to by clients. "*" allows any host. Empty
+	// disables check webhooks.
+	CheckWebhookAllowlist []string `mapstructure:"check_webhook_allowlist"`
 }
 
 // DefaultConsulConfig() returns the canonical defaults for the Nomad
@@ -171,6 +207,30 @@ func (a *ConsulConfig) Merge(b *ConsulConfig) *ConsulConfig {
 	if b.ChecksUseAdvertise != nil {
 		result.ChecksUseAdvertise = helper.BoolToPtr(*b.ChecksUseAdvertise)
 	}
+	if b.CheckCacheMaxAge != 0 {
+		result.CheckCacheMaxAge = b.CheckCacheMaxAge
+	}
+	if b.MaxChecksPerAlloc != 0 {
+		result.MaxChecksPerAlloc = b.MaxChecksPerAlloc
+	}
+	if b.ShutdownCheckRetries != 0 {
+		result.ShutdownCheckRetries = b.ShutdownCheckRetries
+	}
+	if b.CheckErrorSummaryInterval != 0 {
+		result.CheckErrorSummaryInterval = b.CheckErrorSummaryInterval
+	}
+	if b.CheckWorkers != 0 {
+		result.CheckWorkers = b.CheckWorkers
+	}
+	if b.CheckShutdownBehavior != "" {
+		result.CheckShutdownBehavior = b.CheckShutdownBehavior
+	}
+	if b.CheckTracing != nil {
+		result.CheckTracing = helper.BoolToPtr(*b.CheckTracing)
+	}
+	if len(b.CheckWebhookAllowlist) != 0 {
+		result.CheckWebhookAllowlist = helper.CopySliceString(b.CheckWebhookAllowlist)
+	}
 	return result
 }
 
@@ -258,6 +318,10 @@ func (c *ConsulConfig) Copy() *ConsulConfig {
 	if nc.ClientAutoJoin != nil {
 		nc.ClientAutoJoin = helper.BoolToPtr(*nc.ClientAutoJoin)
 	}
+	if nc.CheckTracing != nil {
+		nc.CheckTracing = helper.BoolToPtr(*nc.CheckTracing)
+	}
+	nc.CheckWebhookAllowlist = helper.CopySliceString(nc.CheckWebhookAllowlist)
 
 	return nc
 }
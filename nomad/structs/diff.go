@@ -646,6 +646,11 @@ func serviceCheckDiff(old, new *ServiceCheck, contextual bool) *ObjectDiff {
 		diff.Objects = append(diff.Objects, headerDiff)
 	}
 
+	// Diff StatusNames
+	if namesDiff := checkStatusNamesDiff(old.StatusNames, new.StatusNames, contextual); namesDiff != nil {
+		diff.Objects = append(diff.Objects, namesDiff)
+	}
+
 	// Diff check_restart
 	if crDiff := checkRestartDiff(old.CheckRestart, new.CheckRestart, contextual); crDiff != nil {
 		diff.Objects = append(diff.Objects, crDiff)
@@ -679,6 +684,26 @@ func checkHeaderDiff(old, new map[string][]string, contextual bool) *ObjectDiff
 	return diff
 }
 
+// checkStatusNamesDiff returns the diff of two service check status name
+// mappings. If contextual diff is enabled, all fields will be returned, even
+// if no diff occurred.
+func checkStatusNamesDiff(old, new map[string]string, contextual bool) *ObjectDiff {
+	diff := &ObjectDiff{Type: DiffTypeNone, Name: "StatusNames"}
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	} else if len(old) == 0 {
+		diff.Type = DiffTypeAdded
+	} else if len(new) == 0 {
+		diff.Type = DiffTypeDeleted
+	} else {
+		diff.Type = DiffTypeEdited
+	}
+
+	diff.Fields = fieldDiffs(old, new, contextual)
+	return diff
+}
+
 // checkRestartDiff returns the diff of two service check check_restart
 // objects. If contextual diff is enabled, all fields will be returned, even if
 // no diff occurred.
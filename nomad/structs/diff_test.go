@@ -4221,12 +4221,30 @@ func TestTaskDiff(t *testing.T) {
 								Type: DiffTypeAdded,
 								Name: "Check",
 								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeAdded,
+										Name: "AlignToClock",
+										Old:  "",
+										New:  "false",
+									},
+									{
+										Type: DiffTypeAdded,
+										Name: "Base64Output",
+										Old:  "",
+										New:  "false",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "Command",
 										Old:  "",
 										New:  "foo",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "Dedupe",
+										Old:  "",
+										New:  "false",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "GRPCUseTLS",
@@ -4239,12 +4257,24 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "",
 										New:  "1000000000",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "KillGrace",
+										Old:  "",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "Name",
 										Old:  "",
 										New:  "bam",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "Nice",
+										Old:  "",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "Path",
@@ -4257,6 +4287,36 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "",
 										New:  "http",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "RlimitCPU",
+										Old:  "",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeAdded,
+										Name: "RlimitMemoryMB",
+										Old:  "",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeAdded,
+										Name: "SeparateStderr",
+										Old:  "",
+										New:  "false",
+									},
+									{
+										Type: DiffTypeAdded,
+										Name: "StartOrder",
+										Old:  "",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeAdded,
+										Name: "StreamInterval",
+										Old:  "",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "TLSSkipVerify",
@@ -4275,18 +4335,42 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "",
 										New:  "http",
 									},
+									{
+										Type: DiffTypeAdded,
+										Name: "WebhookInterval",
+										Old:  "",
+										New:  "0",
+									},
 								},
 							},
 							{
 								Type: DiffTypeDeleted,
 								Name: "Check",
 								Fields: []*FieldDiff{
+									{
+										Type: DiffTypeDeleted,
+										Name: "AlignToClock",
+										Old:  "false",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "Base64Output",
+										Old:  "false",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "Command",
 										Old:  "foo",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "Dedupe",
+										Old:  "false",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "GRPCUseTLS",
@@ -4299,12 +4383,24 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "1000000000",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "KillGrace",
+										Old:  "0",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "Name",
 										Old:  "foo",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "Nice",
+										Old:  "0",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "Path",
@@ -4317,6 +4413,36 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "http",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "RlimitCPU",
+										Old:  "0",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "RlimitMemoryMB",
+										Old:  "0",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "SeparateStderr",
+										Old:  "false",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "StartOrder",
+										Old:  "0",
+										New:  "",
+									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "StreamInterval",
+										Old:  "0",
+										New:  "",
+									},
 									{
 										Type: DiffTypeDeleted,
 										Name: "TLSSkipVerify",
@@ -4335,6 +4461,12 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "http",
 										New:  "",
 									},
+									{
+										Type: DiffTypeDeleted,
+										Name: "WebhookInterval",
+										Old:  "0",
+										New:  "",
+									},
 								},
 								Objects: []*ObjectDiff{
 									{
@@ -4443,12 +4575,36 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "",
 										New:  "",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "AlignToClock",
+										Old:  "false",
+										New:  "false",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "Base64Output",
+										Old:  "false",
+										New:  "false",
+									},
 									{
 										Type: DiffTypeNone,
 										Name: "Command",
 										Old:  "foo",
 										New:  "foo",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "Dedupe",
+										Old:  "false",
+										New:  "false",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "DefaultOutput",
+										Old:  "",
+										New:  "",
+									},
 									{
 										Type: DiffTypeNone,
 										Name: "GRPCService",
@@ -4473,6 +4629,12 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "1000000000",
 										New:  "1000000000",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "KillGrace",
+										Old:  "0",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeAdded,
 										Name: "Method",
@@ -4485,6 +4647,12 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "foo",
 										New:  "foo",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "Nice",
+										Old:  "0",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeNone,
 										Name: "Path",
@@ -4503,6 +4671,36 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "http",
 										New:  "http",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "RlimitCPU",
+										Old:  "0",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "RlimitMemoryMB",
+										Old:  "0",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "SeparateStderr",
+										Old:  "false",
+										New:  "false",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "StartOrder",
+										Old:  "0",
+										New:  "0",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "StreamInterval",
+										Old:  "0",
+										New:  "0",
+									},
 									{
 										Type: DiffTypeNone,
 										Name: "TLSSkipVerify",
@@ -4515,12 +4713,30 @@ func TestTaskDiff(t *testing.T) {
 										Old:  "1000000000",
 										New:  "1000000000",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "TruncateFrom",
+										Old:  "",
+										New:  "",
+									},
 									{
 										Type: DiffTypeEdited,
 										Name: "Type",
 										Old:  "http",
 										New:  "tcp",
 									},
+									{
+										Type: DiffTypeNone,
+										Name: "Webhook",
+										Old:  "",
+										New:  "",
+									},
+									{
+										Type: DiffTypeNone,
+										Name: "WebhookInterval",
+										Old:  "0",
+										New:  "0",
+									},
 								},
 								Objects: []*ObjectDiff{
 									{
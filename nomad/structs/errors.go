@@ -8,6 +8,7 @@ import (
 
 const (
 	errNoLeader            = "No cluster leader"
+	errNoQuorum            = "No cluster leader: no quorum for consistent reads"
 	errNoRegionPath        = "No path to region"
 	errTokenNotFound       = "ACL token not found"
 	errPermissionDenied    = "Permission denied"
@@ -27,6 +28,7 @@ const (
 
 var (
 	ErrNoLeader            = errors.New(errNoLeader)
+	ErrNoQuorum            = errors.New(errNoQuorum)
 	ErrNoRegionPath        = errors.New(errNoRegionPath)
 	ErrTokenNotFound       = errors.New(errTokenNotFound)
 	ErrPermissionDenied    = errors.New(errPermissionDenied)
@@ -41,6 +43,12 @@ func IsErrNoLeader(err error) bool {
 	return err != nil && strings.Contains(err.Error(), errNoLeader)
 }
 
+// IsErrNoQuorum returns whether the error is due to a read being rejected
+// because there is no quorum. Such errors are also no leader errors.
+func IsErrNoQuorum(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errNoQuorum)
+}
+
 // IsErrNoRegionPath returns whether the error is due to there being no path to
 // the given region.
 func IsErrNoRegionPath(err error) bool {
@@ -63,6 +63,41 @@ type RaftPeerByIDRequest struct {
 	WriteRequest
 }
 
+// EvacuateRegionRequest is used by the Operator endpoint to decommission every
+// server in a region.
+type EvacuateRegionRequest struct {
+	// WriteRequest holds the Region to evacuate.
+	WriteRequest
+}
+
+// ServerLeaveRequest is used by the leader of a region being evacuated to
+// instruct one of its servers to leave the cluster.
+type ServerLeaveRequest struct {
+	// WriteRequest holds the Region of the server.
+	WriteRequest
+}
+
+// DemoteVoterRequest is used by the Operator endpoint to convert a server from
+// a raft voter to a non-voter.
+type DemoteVoterRequest struct {
+	// NodeID is the ID of the server to demote.
+	NodeID string
+
+	// WriteRequest holds the Region of the server.
+	WriteRequest
+}
+
+// ServerNonVoterRequest is used by the leader to instruct one of its servers
+// to tag itself as a non-voter while it's being demoted, or to remove the tag
+// if the demotion fails.
+type ServerNonVoterRequest struct {
+	// NonVoter is whether the server should be tagged as a non-voter.
+	NonVoter bool
+
+	// WriteRequest holds the Region of the server.
+	WriteRequest
+}
+
 // AutopilotSetConfigRequest is used by the Operator endpoint to update the
 // current Autopilot configuration of the cluster.
 type AutopilotSetConfigRequest struct {
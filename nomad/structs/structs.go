@@ -792,6 +792,64 @@ type ServerMember struct {
 	DelegateCur uint8
 }
 
+// ServiceHealthRequest is used to query the health of a service's checks
+// across all nodes
+type ServiceHealthRequest struct {
+	ServiceName string
+	QueryOptions
+}
+
+// ServiceHealthResponse is the aggregated health of a service's checks
+type ServiceHealthResponse struct {
+	// Status is the worst status of any of the service's checks
+	Status string
+
+	// Nodes is the health of the service's checks on each node, sorted by
+	// node name
+	Nodes []*NodeServiceHealth
+
+	QueryMeta
+}
+
+// NodeServiceHealth is the health of a service's checks on a single node
+type NodeServiceHealth struct {
+	// Node is the name of the node the checks are registered on
+	Node string
+
+	// Status is the worst status of the service's checks on the node
+	Status string
+
+	// Checks are the service's checks on the node
+	Checks []*ServiceCheckHealth
+}
+
+// ServiceCheckHealth is the status of a single check
+type ServiceCheckHealth struct {
+	CheckID string
+	Name    string
+	Status  string
+	Output  string
+}
+
+// ReplicationStateResponse is the leader's view of raft replication to each of
+// its followers
+type ReplicationStateResponse struct {
+	// Followers is the replication state of each follower keyed by node ID
+	Followers map[string]ReplState
+}
+
+// ReplState is the raft replication state of a single follower
+type ReplState struct {
+	// MatchIndex is the highest log index the follower is known to have
+	MatchIndex uint64
+
+	// NextIndex is the next log index the leader will send the follower
+	NextIndex uint64
+
+	// LastContact is when the follower was last in contact with the leader
+	LastContact time.Time
+}
+
 // DeriveVaultTokenRequest is used to request wrapped Vault tokens for the
 // following tasks in the given allocation
 type DeriveVaultTokenRequest struct {
@@ -4882,26 +4940,49 @@ const (
 	minCheckTimeout = 1 * time.Second
 )
 
+const (
+	// CheckTruncateHead keeps the beginning of oversized script check output
+	CheckTruncateHead = "head"
+
+	// CheckTruncateTail keeps the end of oversized script check output
+	CheckTruncateTail = "tail"
+)
+
 // The ServiceCheck data model represents the consul health check that
 // Nomad registers for a Task
 type ServiceCheck struct {
-	Name          string              // Name of the check, defaults to id
-	Type          string              // Type of the check - tcp, http, docker and script
-	Command       string              // Command is the command to run for script checks
-	Args          []string            // Args is a list of arguments for script checks
-	Path          string              // path of the health check url for http type check
-	Protocol      string              // Protocol to use if check is http, defaults to http
-	PortLabel     string              // The port to use for tcp/http checks
-	AddressMode   string              // 'host' to use host ip:port or 'driver' to use driver's
-	Interval      time.Duration       // Interval of the check
-	Timeout       time.Duration       // Timeout of the response from the check before consul fails the check
-	InitialStatus string              // Initial status of the check
-	TLSSkipVerify bool                // Skip TLS verification when Protocol=https
-	Method        string              // HTTP Method to use (GET by default)
-	Header        map[string][]string // HTTP Headers for Consul to set when making HTTP checks
-	CheckRestart  *CheckRestart       // If and when a task should be restarted based on checks
-	GRPCService   string              // Service for GRPC checks
-	GRPCUseTLS    bool                // Whether or not to use TLS for GRPC checks
+	Name            string              // Name of the check, defaults to id
+	Type            string              // Type of the check - tcp, http, docker and script
+	Command         string              // Command is the command to run for script checks
+	Args            []string            // Args is a list of arguments for script checks
+	Path            string              // path of the health check url for http type check
+	Protocol        string              // Protocol to use if check is http, defaults to http
+	PortLabel       string              // The port to use for tcp/http checks
+	AddressMode     string              // 'host' to use host ip:port or 'driver' to use driver's
+	Interval        time.Duration       // Interval of the check
+	Timeout         time.Duration       // Timeout of the response from the check before consul fails the check
+	InitialStatus   string              // Initial status of the check
+	TLSSkipVerify   bool                // Skip TLS verification when Protocol=https
+	Method          string              // HTTP Method to use (GET by default)
+	Header          map[string][]string // HTTP Headers for Consul to set when making HTTP checks
+	CheckRestart    *CheckRestart       // If and when a task should be restarted based on checks
+	GRPCService     string              // Service for GRPC checks
+	GRPCUseTLS      bool                // Whether or not to use TLS for GRPC checks
+	StreamInterval  time.Duration       // How often to forward partial output of running script checks
+	DefaultOutput   string              // Output reported when a script check prints nothing
+	Dedupe          bool                // Share one run of identical script checks across tasks
+	KillGrace       time.Duration       // How long timed out script checks may clean up before being killed
+	TruncateFrom    string              // Which end of oversized script check output to keep
+	Webhook         string              // URL notified by the client when a script check's status changes
+	WebhookInterval time.Duration       // Minimum time between webhook notifications of a script check
+	StatusNames     map[string]string   // Overrides the statuses script checks report to Consul
+	AlignToClock    bool                // Run script checks on wall-clock multiples of Interval
+	Nice            int                 // Scheduling priority of script check processes
+	RlimitCPU       time.Duration       // CPU time script check processes may use
+	RlimitMemoryMB  int                 // Address space in MB script check processes may use
+	Base64Output    bool                // Base64 encode script check output before reporting it
+	StartOrder      int                 // Script checks registered together start in ascending order
+	SeparateStderr  bool                // Capture script check stderr separately and label it on failure
 }
 
 func (sc *ServiceCheck) Copy() *ServiceCheck {
@@ -4912,6 +4993,7 @@ func (sc *ServiceCheck) Copy() *ServiceCheck {
 	*nsc = *sc
 	nsc.Args = helper.CopySliceString(sc.Args)
 	nsc.Header = helper.CopyMapStringSliceString(sc.Header)
+	nsc.StatusNames = helper.CopyMapStringString(sc.StatusNames)
 	nsc.CheckRestart = sc.CheckRestart.Copy()
 	return nsc
 }
@@ -4933,6 +5015,10 @@ func (sc *ServiceCheck) Canonicalize(serviceName string) {
 		}
 	}
 
+	if len(sc.StatusNames) == 0 {
+		sc.StatusNames = nil
+	}
+
 	if sc.Name == "" {
 		sc.Name = fmt.Sprintf("service: %q check", serviceName)
 	}
@@ -4978,6 +5064,111 @@ func (sc *ServiceCheck) validate() error {
 		return fmt.Errorf("timeout (%v) is lower than required minimum timeout %v", sc.Timeout, minCheckInterval)
 	}
 
+	// Script checks report to Consul via a TTL and don't start a run until the
+	// previous one finishes, so only their timeout may exceed the interval
+	if sc.Timeout > sc.Interval && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("timeout (%v) cannot be greater than interval (%v)", sc.Timeout, sc.Interval)
+	}
+
+	if sc.StreamInterval < 0 {
+		return fmt.Errorf("stream_interval (%v) must be >= 0", sc.StreamInterval)
+	} else if sc.StreamInterval > 0 && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("stream_interval is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.DefaultOutput != "" && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("default_output is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.Dedupe && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("dedupe is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.KillGrace < 0 {
+		return fmt.Errorf("kill_grace (%v) must be >= 0", sc.KillGrace)
+	} else if sc.KillGrace > 0 && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("kill_grace is only valid for %q checks", ServiceCheckScript)
+	}
+
+	switch sc.TruncateFrom {
+	case "":
+	case CheckTruncateHead, CheckTruncateTail:
+		if strings.ToLower(sc.Type) != ServiceCheckScript {
+			return fmt.Errorf("truncate_from is only valid for %q checks", ServiceCheckScript)
+		}
+	default:
+		return fmt.Errorf(`truncate_from must be %q or %q`, CheckTruncateHead, CheckTruncateTail)
+	}
+
+	if sc.Webhook != "" {
+		if strings.ToLower(sc.Type) != ServiceCheckScript {
+			return fmt.Errorf("webhook is only valid for %q checks", ServiceCheckScript)
+		}
+		u, err := url.Parse(sc.Webhook)
+		if err != nil {
+			return fmt.Errorf("webhook must be a valid URL: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("webhook must be an http or https URL")
+		}
+	}
+	if sc.WebhookInterval < 0 {
+		return fmt.Errorf("webhook_interval (%v) must be >= 0", sc.WebhookInterval)
+	} else if sc.WebhookInterval > 0 && sc.Webhook == "" {
+		return fmt.Errorf("webhook_interval requires a webhook")
+	}
+
+	if sc.AlignToClock && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("align_to_clock is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.Base64Output && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("base64_output is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.SeparateStderr && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("separate_stderr is only valid for %q checks", ServiceCheckScript)
+	}
+
+	if sc.StartOrder != 0 && strings.ToLower(sc.Type) != ServiceCheckScript {
+		return fmt.Errorf("start_order is only valid for %q checks", ServiceCheckScript)
+	}
+	if sc.StartOrder < 0 {
+		return fmt.Errorf("start_order must not be negative: %d", sc.StartOrder)
+	}
+
+	// Validate script resource limits
+	if sc.Nice != 0 || sc.RlimitCPU != 0 || sc.RlimitMemoryMB != 0 {
+		if strings.ToLower(sc.Type) != ServiceCheckScript {
+			return fmt.Errorf("nice and rlimits are only valid for %q checks", ServiceCheckScript)
+		}
+	}
+	if sc.Nice < 0 || sc.Nice > 19 {
+		return fmt.Errorf("nice must be between 0 and 19: %d", sc.Nice)
+	}
+	if sc.RlimitCPU < 0 || (sc.RlimitCPU > 0 && sc.RlimitCPU < time.Second) {
+		return fmt.Errorf("rlimit_cpu must be at least 1s: %v", sc.RlimitCPU)
+	}
+	if sc.RlimitMemoryMB < 0 {
+		return fmt.Errorf("rlimit_memory must not be negative: %d", sc.RlimitMemoryMB)
+	}
+
+	if len(sc.StatusNames) > 0 {
+		if strings.ToLower(sc.Type) != ServiceCheckScript {
+			return fmt.Errorf("status_names is only valid for %q checks", ServiceCheckScript)
+		}
+		for status, name := range sc.StatusNames {
+			switch status {
+			case api.HealthPassing, api.HealthWarning, api.HealthCritical:
+			default:
+				return fmt.Errorf(`invalid status_names status (%s), must be one of %q, %q or %q`, status, api.HealthPassing, api.HealthWarning, api.HealthCritical)
+			}
+			if name == "" {
+				return fmt.Errorf("status_names must not map %q to an empty name", status)
+			}
+		}
+	}
+
 	// Validate InitialStatus
 	switch sc.InitialStatus {
 	case "":
@@ -5064,6 +5255,86 @@ func (sc *ServiceCheck) Hash(serviceID string) string {
 		io.WriteString(h, "true")
 	}
 
+	// Fields below are prefixed with their name so moving a value from one
+	// field to another changes the ID
+
+	// Only include StreamInterval if set to maintain ID stability
+	if sc.StreamInterval != 0 {
+		io.WriteString(h, "stream_interval="+sc.StreamInterval.String())
+	}
+
+	// Only include DefaultOutput if set to maintain ID stability
+	if sc.DefaultOutput != "" {
+		io.WriteString(h, "default_output="+sc.DefaultOutput)
+	}
+
+	// Only include Dedupe if set to maintain ID stability
+	if sc.Dedupe {
+		io.WriteString(h, "dedupe")
+	}
+
+	// Only include KillGrace if set to maintain ID stability
+	if sc.KillGrace != 0 {
+		io.WriteString(h, "kill_grace="+sc.KillGrace.String())
+	}
+
+	// Only include TruncateFrom if set to maintain ID stability
+	if sc.TruncateFrom != "" {
+		io.WriteString(h, "truncate_from="+sc.TruncateFrom)
+	}
+
+	// Only include Webhook if set to maintain ID stability
+	if sc.Webhook != "" {
+		io.WriteString(h, "webhook="+sc.Webhook)
+	}
+
+	// Only include WebhookInterval if set to maintain ID stability
+	if sc.WebhookInterval != 0 {
+		io.WriteString(h, "webhook_interval="+sc.WebhookInterval.String())
+	}
+
+	// Only include StatusNames if set to maintain ID stability. Sort the
+	// pairs since map iteration order isn't stable.
+	if len(sc.StatusNames) > 0 {
+		names := make([]string, 0, len(sc.StatusNames))
+		for k, v := range sc.StatusNames {
+			names = append(names, k+"="+v)
+		}
+		sort.Strings(names)
+		io.WriteString(h, "status_names="+strings.Join(names, ","))
+	}
+
+	// Only include AlignToClock if set to maintain ID stability
+	if sc.AlignToClock {
+		io.WriteString(h, "align_to_clock")
+	}
+
+	// Only include script resource limits if set to maintain ID stability
+	if sc.Nice != 0 {
+		io.WriteString(h, "nice="+strconv.Itoa(sc.Nice))
+	}
+	if sc.RlimitCPU != 0 {
+		io.WriteString(h, "rlimit_cpu="+sc.RlimitCPU.String())
+	}
+	if sc.RlimitMemoryMB != 0 {
+		io.WriteString(h, "rlimit_memory="+strconv.Itoa(sc.RlimitMemoryMB))
+	}
+
+	// Only include Base64Output if set to maintain ID stability
+	if sc.Base64Output {
+		io.WriteString(h, "base64_output")
+	}
+
+	// Only include StartOrder if set to maintain ID stability
+	if sc.StartOrder != 0 {
+		io.WriteString(h, "start_order="+strconv.Itoa(sc.StartOrder))
+	}
+
+	// Only include SeparateStderr if set to maintain ID stability
+	if sc.SeparateStderr {
+		io.WriteString(h, "separate_stderr")
+	}
+
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
@@ -1174,6 +1174,34 @@ func TestTask_Validate_Service_Check(t *testing.T) {
 	}
 }
 
+// TestTask_Validate_Service_Check_Interval asserts checks must have a
+// positive interval and that only script checks may have a timeout greater
+// than their interval.
+func TestTask_Validate_Service_Check_Interval(t *testing.T) {
+	check := ServiceCheck{
+		Name:    "check-name",
+		Type:    ServiceCheckTCP,
+		Timeout: 2 * time.Second,
+	}
+	err := check.validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing required value interval")
+
+	check.Interval = -10 * time.Second
+	err = check.validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot be lower than")
+
+	check.Interval = time.Second
+	err = check.validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot be greater than interval")
+
+	check.Type = ServiceCheckScript
+	check.Command = "/bin/true"
+	require.NoError(t, check.validate())
+}
+
 // TestTask_Validate_Service_Check_AddressMode asserts that checks do not
 // inherit address mode but do inherit ports.
 func TestTask_Validate_Service_Check_AddressMode(t *testing.T) {
@@ -1374,6 +1402,27 @@ func TestTask_Validate_Service_Check_GRPC(t *testing.T) {
 	assert.NoError(t, service.Validate())
 }
 
+func TestTask_Validate_Service_Check_StreamInterval(t *testing.T) {
+	t.Parallel()
+	check := &ServiceCheck{
+		Type:           ServiceCheckScript,
+		Command:        "/bin/true",
+		Interval:       time.Second,
+		Timeout:        time.Second,
+		StreamInterval: time.Second,
+	}
+	assert.NoError(t, check.validate())
+
+	// Negative intervals are invalid
+	check.StreamInterval = -1
+	assert.Error(t, check.validate())
+
+	// Only script checks may stream output
+	check.Type = ServiceCheckTCP
+	check.StreamInterval = time.Second
+	assert.Error(t, check.validate())
+}
+
 func TestTask_Validate_Service_Check_CheckRestart(t *testing.T) {
 	t.Parallel()
 	invalidCheckRestart := &CheckRestart{
@@ -2150,6 +2199,57 @@ func TestDistinctCheckID(t *testing.T) {
 
 }
 
+// TestServiceCheck_Hash_Labeled asserts checks setting the same value on
+// different script check fields have different IDs.
+func TestServiceCheck_Hash_Labeled(t *testing.T) {
+	base := func(f func(*ServiceCheck)) string {
+		c := &ServiceCheck{
+			Name:     "script-health",
+			Type:     ServiceCheckScript,
+			Command:  "/bin/true",
+			Interval: 10 * time.Second,
+			Timeout:  2 * time.Second,
+		}
+		f(c)
+		return c.Hash("123")
+	}
+
+	cases := map[string][]func(*ServiceCheck){
+		"ints": {
+			func(c *ServiceCheck) { c.Nice = 5 },
+			func(c *ServiceCheck) { c.RlimitMemoryMB = 5 },
+			func(c *ServiceCheck) { c.StartOrder = 5 },
+		},
+		"durations": {
+			func(c *ServiceCheck) { c.KillGrace = 5 * time.Second },
+			func(c *ServiceCheck) { c.StreamInterval = 5 * time.Second },
+			func(c *ServiceCheck) { c.WebhookInterval = 5 * time.Second },
+			func(c *ServiceCheck) { c.RlimitCPU = 5 * time.Second },
+		},
+		"bools": {
+			func(c *ServiceCheck) { c.Dedupe = true },
+			func(c *ServiceCheck) { c.GRPCUseTLS = true },
+			func(c *ServiceCheck) { c.AlignToClock = true },
+			func(c *ServiceCheck) { c.Base64Output = true },
+		},
+		"strings": {
+			func(c *ServiceCheck) { c.DefaultOutput = "http://example.com" },
+			func(c *ServiceCheck) { c.Webhook = "http://example.com" },
+		},
+	}
+	for name, fields := range cases {
+		t.Run(name, func(t *testing.T) {
+			seen := make(map[string]int)
+			for i, f := range fields {
+				h := base(f)
+				j, ok := seen[h]
+				require.False(t, ok, "fields %d and %d hash the same", j, i)
+				seen[h] = i
+			}
+		})
+	}
+}
+
 func TestService_Canonicalize(t *testing.T) {
 	job := "example"
 	taskGroup := "cache"
@@ -0,0 +1,182 @@
+package nomad
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/coordinate"
+	"github.com/hashicorp/serf/serf"
+)
+
+// Topology is a graph of the servers known to this server and the
+// connectivity between them.
+type Topology struct {
+	// Nodes are the servers, sorted by name
+	Nodes []*TopologyNode
+
+	// Edges connect each server to the servers it considers alive, sorted by
+	// their endpoints
+	Edges []*TopologyEdge
+}
+
+// TopologyNode is a server in the Topology.
+type TopologyNode struct {
+	// Name is the server's serf member name
+	Name string
+
+	// ID is the server's node ID
+	ID string
+
+	Region     string
+	Datacenter string
+	Address    string
+
+	// Status is the server's serf status as seen by this server. It is empty
+	// for servers only found in the Raft configuration.
+	Status string
+
+	// Suffrage is the server's suffrage in the Raft configuration of this
+	// server's region. It is empty for servers that aren't Raft peers.
+	Suffrage string
+
+	// Leader is true if the server is the leader of this server's region
+	Leader bool
+}
+
+// TopologyEdge indicates the From server considers the To server alive.
+type TopologyEdge struct {
+	From string
+	To   string
+
+	// RTT is the round trip time between the servers estimated from their
+	// network coordinates. It is zero if unknown.
+	RTT time.Duration
+}
+
+// TopologySnapshot returns the current topology of the servers, combining the
+// serf membership, the Raft configuration of this server's region and the
+// servers' network coordinates. Edges are only included for servers whose
+// view of the membership could be queried.
+func (s *Server) TopologySnapshot() (*Topology, error) {
+	future := s.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, fmt.Errorf("failed to get raft configuration: %v", err)
+	}
+	leader := s.raft.Leader()
+
+	// Index the Raft peers by both ID and address to support all Raft
+	// protocol versions
+	raftServers := make(map[string]raft.Server)
+	for _, server := range future.Configuration().Servers {
+		raftServers[string(server.ID)] = server
+		raftServers[string(server.Address)] = server
+	}
+
+	topology := &Topology{}
+	seen := make(map[raft.ServerID]struct{})
+	for _, m := range s.serf.Members() {
+		ok, parts := isNomadServer(m)
+		if !ok {
+			continue
+		}
+		node := &TopologyNode{
+			Name:       m.Name,
+			ID:         parts.ID,
+			Region:     parts.Region,
+			Datacenter: parts.Datacenter,
+			Address:    parts.Addr.String(),
+			Status:     m.Status.String(),
+		}
+		if parts.Region == s.config.Region {
+			server, ok := raftServers[parts.ID]
+			if !ok {
+				server, ok = raftServers[parts.Addr.String()]
+			}
+			if ok {
+				seen[server.ID] = struct{}{}
+				node.Suffrage = server.Suffrage.String()
+				node.Leader = server.Address == leader
+			}
+		}
+		topology.Nodes = append(topology.Nodes, node)
+	}
+
+	// Include Raft peers unknown to serf, such as servers that were never
+	// reaped after leaving
+	for _, server := range future.Configuration().Servers {
+		if _, ok := seen[server.ID]; ok {
+			continue
+		}
+		topology.Nodes = append(topology.Nodes, &TopologyNode{
+			Name:     string(server.ID),
+			ID:       string(server.ID),
+			Region:   s.config.Region,
+			Address:  string(server.Address),
+			Suffrage: server.Suffrage.String(),
+			Leader:   server.Address == leader,
+		})
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		return topology.Nodes[i].Name < topology.Nodes[j].Name
+	})
+
+	// Each server that responds is connected to the members we know of that
+	// it doesn't consider failed
+	local := s.serf.LocalMember().Name
+	members := s.serf.Members()
+	for from, failed := range s.failedMemberViews() {
+		unreachable := make(map[string]struct{}, len(failed))
+		for _, name := range failed {
+			unreachable[name] = struct{}{}
+		}
+		for _, m := range members {
+			if _, ok := unreachable[m.Name]; ok || m.Name == from {
+				continue
+			}
+			if m.Status != serf.StatusAlive && m.Status != serf.StatusFailed {
+				continue
+			}
+			topology.Edges = append(topology.Edges, &TopologyEdge{
+				From: from,
+				To:   m.Name,
+				RTT:  s.estimateRTT(local, from, m.Name),
+			})
+		}
+	}
+	sort.Slice(topology.Edges, func(i, j int) bool {
+		a, b := topology.Edges[i], topology.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	return topology, nil
+}
+
+// estimateRTT returns the round trip time between two serf members estimated
+// from their network coordinates, or zero if either coordinate is unknown.
+func (s *Server) estimateRTT(local, from, to string) time.Duration {
+	coord := func(name string) *coordinate.Coordinate {
+		if name == local {
+			c, err := s.serf.GetCoordinate()
+			if err != nil {
+				return nil
+			}
+			return c
+		}
+		c, ok := s.serf.GetCachedCoordinate(name)
+		if !ok {
+			return nil
+		}
+		return c
+	}
+
+	a, b := coord(from), coord(to)
+	if a == nil || b == nil || !a.IsCompatibleWith(b) {
+		return 0
+	}
+	return a.DistanceTo(b)
+}
@@ -0,0 +1,86 @@
+package nomad
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/serf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_TopologySnapshot(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	s1 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+	})
+	defer s1.Shutdown()
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	s2 := TestServer(t, func(c *Config) {
+		c.Region = "region1"
+		c.DevMode = false
+		c.DevDisableBootstrap = true
+		c.DataDir = path.Join(dir, "node2")
+	})
+	defer s2.Shutdown()
+
+	s3 := TestServer(t, func(c *Config) {
+		c.Region = "region2"
+	})
+	defer s3.Shutdown()
+
+	TestJoin(t, s1, s2, s3)
+	testutil.WaitForResult(func() (bool, error) {
+		for _, s := range []*Server{s1, s2, s3} {
+			if n := len(s.Members()); n != 3 {
+				return false, fmt.Errorf("expected 3 members; got %d", n)
+			}
+		}
+		peers, _ := s1.numPeers()
+		return peers == 2, fmt.Errorf("expected 2 peers; got %d", peers)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	topology, err := s1.TopologySnapshot()
+	require.NoError(err)
+	require.Len(topology.Nodes, 3)
+
+	nodes := make(map[string]*TopologyNode)
+	for _, n := range topology.Nodes {
+		nodes[n.Name] = n
+	}
+
+	n1 := nodes[s1.LocalMember().Name]
+	require.NotNil(n1)
+	require.Equal("region1", n1.Region)
+	require.Equal(raft.Voter.String(), n1.Suffrage)
+	require.Equal(serf.StatusAlive.String(), n1.Status)
+	require.True(n1.Leader)
+
+	n2 := nodes[s2.LocalMember().Name]
+	require.NotNil(n2)
+	require.Equal("region1", n2.Region)
+	require.Equal(raft.Voter.String(), n2.Suffrage)
+	require.False(n2.Leader)
+
+	// Servers in other regions aren't part of the Raft configuration
+	n3 := nodes[s3.LocalMember().Name]
+	require.NotNil(n3)
+	require.Equal("region2", n3.Region)
+	require.Empty(n3.Suffrage)
+	require.False(n3.Leader)
+
+	// Every server can reach every other server
+	require.Len(topology.Edges, 6)
+	for _, e := range topology.Edges {
+		require.NotEqual(e.From, e.To)
+	}
+}
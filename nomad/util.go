@@ -57,6 +57,41 @@ func (s *serverParts) Copy() *serverParts {
 	return ns
 }
 
+// knownServerTags are the serf tags Nomad servers advertise
+var knownServerTags = map[string]struct{}{
+	"role":              {},
+	"region":            {},
+	"dc":                {},
+	"vsn":               {},
+	"mvn":               {},
+	"build":             {},
+	"raft_vsn":          {},
+	"id":                {},
+	"rpc_addr":          {},
+	"port":              {},
+	"bootstrap":         {},
+	"expect":            {},
+	"nonvoter":          {},
+	AutopilotRZTag:      {},
+	AutopilotVersionTag: {},
+}
+
+// unknownServerTags returns the tags of a member that aren't known to this
+// version of Nomad or nil if there are none.
+func unknownServerTags(m serf.Member) map[string]string {
+	var unknown map[string]string
+	for k, v := range m.Tags {
+		if _, ok := knownServerTags[k]; ok {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]string)
+		}
+		unknown[k] = v
+	}
+	return unknown
+}
+
 // Returns if a member is a Nomad server. Returns a boolean,
 // and a struct with the various important components
 func isNomadServer(m serf.Member) (bool, *serverParts) {
@@ -154,10 +189,7 @@ func isNomadServer(m serf.Member) (bool, *serverParts) {
 func ServersMeetMinimumVersion(members []serf.Member, minVersion *version.Version, checkFailedServers bool) bool {
 	for _, member := range members {
 		if valid, parts := isNomadServer(member); valid && (parts.Status == serf.StatusAlive || (checkFailedServers && parts.Status == serf.StatusFailed)) {
-			// Check if the versions match - version.LessThan will return true for
-			// 0.8.0-rc1 < 0.8.0, so we want to ignore the metadata
-			versionsMatch := slicesMatch(minVersion.Segments(), parts.Build.Segments())
-			if parts.Build.LessThan(minVersion) && !versionsMatch {
+			if !meetsMinimumVersion(&parts.Build, minVersion) {
 				return false
 			}
 		}
@@ -166,6 +198,14 @@ func ServersMeetMinimumVersion(members []serf.Member, minVersion *version.Versio
 	return true
 }
 
+// meetsMinimumVersion returns whether build is at least minVersion.
+func meetsMinimumVersion(build, minVersion *version.Version) bool {
+	// Check if the versions match - version.LessThan will return true for
+	// 0.8.0-rc1 < 0.8.0, so we want to ignore the metadata
+	versionsMatch := slicesMatch(minVersion.Segments(), build.Segments())
+	return !build.LessThan(minVersion) || versionsMatch
+}
+
 func slicesMatch(a, b []int) bool {
 	if a == nil && b == nil {
 		return true
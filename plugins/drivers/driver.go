@@ -56,6 +56,14 @@ type DriverPlugin interface {
 	ExecTask(taskID string, cmd []string, timeout time.Duration) (*ExecTaskResult, error)
 }
 
+// ExecOptionsDriver is implemented by drivers that can run a command in a
+// task with more options than ExecTask. It's only available from internal
+// drivers as the driver plugin protocol has no RPC for it; callers should
+// fall back to ExecTask for drivers that don't implement it.
+type ExecOptionsDriver interface {
+	ExecTaskWithOptions(taskID string, opts *ExecOptions) (*ExecTaskResult, error)
+}
+
 // InternalDriverPlugin is an interface that exposes functions that are only
 // implemented by internal driver plugins.
 type InternalDriverPlugin interface {
@@ -347,6 +355,45 @@ type TaskEvent struct {
 	Err error
 }
 
+// ExecOptions configure a command run by ExecTaskWithOptions.
+type ExecOptions struct {
+	// Command is the command to run followed by its arguments
+	Command []string
+
+	// Timeout is how long the command may run
+	Timeout time.Duration
+
+	// KillGrace is how long a command still running at its timeout is given
+	// to exit after being signaled before it's killed. If zero it's killed
+	// immediately.
+	KillGrace time.Duration
+
+	// Nice is the scheduling priority the command runs at
+	Nice int
+
+	// CPULimit is the CPU time the command may use before it's signaled to
+	// exit
+	CPULimit time.Duration
+
+	// MemoryLimitMB is the address space in MB the command may use
+	MemoryLimitMB int
+
+	// Output, if set, is called with each chunk of output as the command
+	// produces it. The full output is still returned once the command exits.
+	Output func([]byte)
+
+	// SeparateStderr returns stderr in ExecTaskResult.Stderr rather than
+	// interleaved with stdout. Output is then only called with stdout.
+	SeparateStderr bool
+
+	// KeepHead keeps the beginning of output longer than CheckBufSize rather
+	// than the end.
+	KeepHead bool
+}
+
+// ExecTaskResult is the result of a command run in a task. A command killed
+// after being given a grace period to exit sets ExitResult.Err to
+// context.DeadlineExceeded.
 type ExecTaskResult struct {
 	Stdout     []byte
 	Stderr     []byte